@@ -0,0 +1,157 @@
+package gospeak
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// SpeechBackend turns a finished speech script into audio. Phrases in the
+// script are separated by {pause} markers, which each backend translates
+// into whatever its engine understands.
+type SpeechBackend interface {
+	Speak(script string, audioOutputFile string) error
+}
+
+// VoiceSettings holds the voice options a backend should use. Empty or
+// negative values leave the engine's own default in place. Language is the
+// language tag of the speech, as given by Locale.Language; when there is no
+// Voice, it picks one that speaks the language.
+type VoiceSettings struct {
+	Voice    string
+	Rate     int
+	Pitch    int
+	Language string
+}
+
+// sayVoices are the voices that come with macOS for the languages of the
+// built-in locales, other than English.
+var sayVoices = map[string]string{
+	"de": "Anna",
+	"es": "Monica",
+}
+
+// language returns the language tag of the speech, en-US by default.
+func (voice VoiceSettings) language() string {
+	if voice.Language == "" {
+		return "en-US"
+	}
+	return voice.Language
+}
+
+// isEnglish reports whether the speech is in English.
+func (voice VoiceSettings) isEnglish() bool {
+	return primaryLanguage(voice.language()) == "en"
+}
+
+// primaryLanguage returns the language of a tag without its region, so
+// es-ES is es.
+func primaryLanguage(tag string) string {
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	return strings.ToLower(tag)
+}
+
+func DefaultVoiceSettings() VoiceSettings {
+	return VoiceSettings{
+		Rate:  -1,
+		Pitch: -1,
+	}
+}
+
+var speechBackendNames = []string{"say", "espeak-ng", "ssml"}
+
+func MakeSpeechBackend(name string, voice VoiceSettings) (SpeechBackend, error) {
+	switch name {
+	case "", "say":
+		return MakeSayBackend(voice), nil
+	case "espeak-ng", "espeak":
+		return MakeESpeakBackend(voice), nil
+	case "ssml":
+		return MakeSSMLBackend(voice, os.Stdout), nil
+	}
+	return nil, fmt.Errorf("unknown speech backend %s (available: %s)", name,
+		strings.Join(speechBackendNames, ", "))
+}
+
+type sayBackend struct {
+	voice VoiceSettings
+}
+
+func MakeSayBackend(voice VoiceSettings) SpeechBackend {
+	return &sayBackend{
+		voice: voice,
+	}
+}
+
+func (sb *sayBackend) Speak(script string, audioOutputFile string) error {
+	return sb.SpeakContext(context.Background(), script, audioOutputFile)
+}
+
+func (sb *sayBackend) SpeakContext(ctx context.Context, script string, audioOutputFile string) error {
+	tempFile, err := ioutil.TempFile(".", "gospeech")
+	if err != nil {
+		return fmt.Errorf("unable to create temp file: %+v", err)
+	}
+	tempFile.WriteString(sayMarkup(script))
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	cmd := exec.CommandContext(ctx, "/usr/bin/say", sb.args(tempFile.Name(), audioOutputFile)...)
+	err = runSpeechCommand(ctx, cmd)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("unable to run say: %+v", err)
+	}
+	return err
+}
+
+func (sb *sayBackend) SynthesizePhrase(ctx context.Context, phrase string, wavFile string) error {
+	args := append(sb.voiceArgs(), "-f", "-", "-o", wavFile,
+		"--file-format=WAVE", "--data-format=LEI16@22050")
+	cmd := exec.CommandContext(ctx, "/usr/bin/say", args...)
+	cmd.Stdin = strings.NewReader(stripMarkers(phrase))
+	return runSpeechCommand(ctx, cmd)
+}
+
+func (sb *sayBackend) PlayWAV(wavFile string) error {
+	return sb.PlayWAVContext(context.Background(), wavFile)
+}
+
+func (sb *sayBackend) PlayWAVContext(ctx context.Context, wavFile string) error {
+	err := runSpeechCommand(ctx, exec.CommandContext(ctx, "/usr/bin/afplay", wavFile))
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("unable to run afplay: %+v", err)
+	}
+	return err
+}
+
+func (sb *sayBackend) args(scriptFile string, audioOutputFile string) []string {
+	args := append([]string{"-f", scriptFile}, sb.voiceArgs()...)
+	if audioOutputFile != "" {
+		args = append(args, "-o", audioOutputFile)
+	}
+	return args
+}
+
+func (sb *sayBackend) voiceArgs() []string {
+	args := []string{}
+	if sb.voice.Voice != "" {
+		args = append(args, "-v", sb.voice.Voice)
+	} else if voice, ok := sayVoices[primaryLanguage(sb.voice.language())]; ok {
+		args = append(args, "-v", voice)
+	}
+	if sb.voice.Rate > 0 {
+		args = append(args, "-r", strconv.Itoa(sb.voice.Rate))
+	}
+	return args
+}
+
+func sayMarkup(script string) string {
+	script = earconPause.ReplaceAllString(script, "[[slnc $1]]")
+	return strings.Replace(stripMarkers(script), "{pause}", "[[slnc 200]]", -1)
+}
@@ -0,0 +1,253 @@
+package gospeak
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+type CaptionFormat string
+
+const (
+	CaptionsVTT CaptionFormat = "vtt"
+	CaptionsSRT CaptionFormat = "srt"
+)
+
+// phrasePause is the silence every backend inserts after a phrase.
+const phrasePause = 200 * time.Millisecond
+
+// defaultWordsPerMinute is used to estimate phrase lengths when the backend
+// can't synthesize phrases individually.
+const defaultWordsPerMinute = 175
+
+// PhraseSynthesizer is implemented by backends that can render a single
+// phrase to a WAV file, which lets captions be timed from real durations.
+// Synthesis stops when ctx is done, and is paused along with the speech ctx
+// belongs to.
+type PhraseSynthesizer interface {
+	SynthesizePhrase(ctx context.Context, phrase string, wavFile string) error
+}
+
+// Caption is one timed cue: a spoken phrase and the source line it came from.
+type Caption struct {
+	Start      time.Duration
+	End        time.Duration
+	Line       int
+	SourceLine string
+	Phrase     string
+}
+
+// SetCaptioned puts the audio output file together from phrases
+// synthesized one at a time, the same phrases that Captions are timed
+// with, so that the captions keep in step with it. The file is then a WAV
+// file. Backends that can't synthesize phrases speak the whole script, and
+// their captions are estimated.
+func (gsp *goSpeaker) SetCaptioned(captioned bool) {
+	gsp.captioned = captioned
+}
+
+func (gsp *goSpeaker) Captions() []Caption {
+	captions := []Caption{}
+	durations := map[string]time.Duration{}
+	for phrase, d := range gsp.phraseDurations {
+		durations[phrase] = d
+	}
+	sources := map[string][]string{}
+
+	var offset time.Duration
+	for _, e := range gsp.events {
+		if earcon, ok := parseEarcon(e.Phrase); e.earcon && ok {
+			offset += time.Duration(earcon.Duration)*time.Millisecond + earconGap
+			continue
+		}
+		d, ok := durations[e.Phrase]
+		if !ok {
+			d = gsp.phraseDuration(e.Phrase)
+			durations[e.Phrase] = d
+		}
+
+		caption := Caption{
+			Start:  offset,
+			End:    offset + d,
+			Line:   e.Start.Line,
+			Phrase: e.Phrase,
+		}
+		if e.Start.IsValid() {
+			lines, ok := sources[e.Start.Filename]
+			if !ok {
+				lines = gsp.sourceLines(e.Start.Filename)
+				sources[e.Start.Filename] = lines
+			}
+			if e.Start.Line <= len(lines) {
+				caption.SourceLine = strings.TrimSpace(lines[e.Start.Line-1])
+			}
+		}
+		captions = append(captions, caption)
+		offset += d + phrasePause
+	}
+	return captions
+}
+
+func (gsp *goSpeaker) WriteCaptions(w io.Writer, format CaptionFormat) error {
+	return WriteCaptions(w, format, gsp.Captions())
+}
+
+func WriteCaptions(w io.Writer, format CaptionFormat, captions []Caption) error {
+	var sb strings.Builder
+	switch format {
+	case CaptionsVTT:
+		sb.WriteString("WEBVTT\n\n")
+	case CaptionsSRT:
+	default:
+		return fmt.Errorf("unknown caption format %s", format)
+	}
+
+	for i, c := range captions {
+		sb.WriteString(fmt.Sprintf("%d\n", i+1))
+		sb.WriteString(captionTime(c.Start, format) + " --> " + captionTime(c.End, format) + "\n")
+		if c.Line > 0 {
+			sb.WriteString(fmt.Sprintf("%d: %s\n", c.Line, captionText(c.SourceLine, format)))
+		}
+		sb.WriteString(captionText(c.Phrase, format) + "\n\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// captionText keeps a cue's text to one line, since a blank line ends the
+// cue, and keeps it from being read as a timing or as markup.
+func captionText(text string, format CaptionFormat) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if format == CaptionsVTT {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+	}
+	// SRT has no escapes
+	return strings.Replace(text, "-->", "-- >", -1)
+}
+
+func captionTime(d time.Duration, format CaptionFormat) string {
+	ms := d.Milliseconds()
+	sep := "."
+	if format == CaptionsSRT {
+		sep = ","
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, (ms/60000)%60, (ms/1000)%60, sep, ms%1000)
+}
+
+func (gsp *goSpeaker) sourceLines(filename string) []string {
+	source := gsp.fileBuffer
+	if gsp.pkg != nil {
+		source = gsp.pkg.sources[filename]
+	}
+	if source == "" {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil
+		}
+		source = string(data)
+	}
+	return strings.Split(source, "\n")
+}
+
+func (gsp *goSpeaker) phraseDuration(phrase string) time.Duration {
+	if synth, ok := gsp.backend.(PhraseSynthesizer); ok {
+		d, err := synthesizedDuration(context.Background(), synth, phrase)
+		if err == nil {
+			return d
+		}
+		gsp.tracef("Estimating duration of %s: %+v\n", phrase, err)
+	}
+	return estimatedDuration(phrase, defaultWordsPerMinute)
+}
+
+func synthesizedDuration(ctx context.Context, synth PhraseSynthesizer, phrase string) (time.Duration, error) {
+	tempFile, err := ioutil.TempFile(".", "gospeech*.wav")
+	if err != nil {
+		return 0, err
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	err = synth.SynthesizePhrase(ctx, phrase, tempFile.Name())
+	if err != nil {
+		return 0, err
+	}
+	return wavDuration(tempFile.Name())
+}
+
+func estimatedDuration(phrase string, wordsPerMinute int) time.Duration {
+	words := len(strings.Fields(phrase))
+	if words == 0 {
+		return 0
+	}
+	return time.Duration(words) * time.Minute / time.Duration(wordsPerMinute)
+}
+
+// wavDuration reads the length of a PCM WAV file from its fmt and data
+// chunks.
+func wavDuration(filename string) (time.Duration, error) {
+	format, samples, err := readWAV(filename)
+	if err != nil {
+		return 0, err
+	}
+	return format.duration(samples), nil
+}
+
+type wavFormat struct {
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+}
+
+func (f wavFormat) byteRate() int {
+	return f.sampleRate * f.channels * f.bitsPerSample / 8
+}
+
+func (f wavFormat) duration(samples []byte) time.Duration {
+	return time.Duration(int64(len(samples)) * int64(time.Second) / int64(f.byteRate()))
+}
+
+// readWAV returns the format and the samples of a PCM WAV file.
+func readWAV(filename string) (wavFormat, []byte, error) {
+	var format wavFormat
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return format, nil, err
+	}
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return format, nil, fmt.Errorf("%s is not a WAV file", filename)
+	}
+
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkID := string(data[pos : pos+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+		switch chunkID {
+		case "fmt ":
+			if body+16 > len(data) {
+				return format, nil, fmt.Errorf("%s has a truncated fmt chunk", filename)
+			}
+			format.channels = int(binary.LittleEndian.Uint16(data[body+2 : body+4]))
+			format.sampleRate = int(binary.LittleEndian.Uint32(data[body+4 : body+8]))
+			format.bitsPerSample = int(binary.LittleEndian.Uint16(data[body+14 : body+16]))
+		case "data":
+			if format.byteRate() == 0 {
+				return format, nil, fmt.Errorf("%s has no usable fmt chunk", filename)
+			}
+			// Engines that stream their output may leave the data size unset.
+			if chunkSize <= 0 || body+chunkSize > len(data) {
+				chunkSize = len(data) - body
+			}
+			return format, data[body : body+chunkSize], nil
+		}
+		pos = body + chunkSize + chunkSize%2
+	}
+	return format, nil, fmt.Errorf("%s has no data chunk", filename)
+}
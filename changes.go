@@ -0,0 +1,186 @@
+package gospeak
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// declaration is a top-level declaration of one version of a file, with
+// the source it was written as.
+type declaration struct {
+	decl   ast.Decl
+	source string
+}
+
+// declarations keys the top-level declarations of a file so that they can
+// be found again in another version of it: functions by their receiver
+// type and name, other declarations by the names they declare.
+func declarations(fset *token.FileSet, file *ast.File, source string) (map[string]declaration, []string) {
+	decls := map[string]declaration{}
+	keys := []string{}
+	for _, decl := range file.Decls {
+		start := decl.Pos()
+		if doc := declDoc(decl); doc != nil {
+			start = doc.Pos()
+		}
+		from, to := fset.Position(start).Offset, fset.Position(decl.End()).Offset
+		if !decl.End().IsValid() || to < from || to > len(source) {
+			// Declarations cut off by a syntax error may not know their end
+			to = len(source)
+		}
+		text := source[from:to]
+
+		key := declarationKey(decl)
+		if _, ok := decls[key]; ok || key == "" {
+			// Blank names, init functions and unreadable code may be
+			// declared more than once, so they are known by their source
+			key += " " + text
+		}
+		decls[key] = declaration{decl: decl, source: text}
+		keys = append(keys, key)
+	}
+	return decls, keys
+}
+
+func declDoc(decl ast.Decl) *ast.CommentGroup {
+	switch v := decl.(type) {
+	case *ast.FuncDecl:
+		return v.Doc
+	case *ast.GenDecl:
+		return v.Doc
+	}
+	return nil
+}
+
+func declarationKey(decl ast.Decl) string {
+	switch v := decl.(type) {
+	case *ast.FuncDecl:
+		if v.Recv != nil && len(v.Recv.List) > 0 {
+			return fmt.Sprintf("func (%s) %s", receiverTypeName(v.Recv.List[0].Type), v.Name.String())
+		}
+		return "func " + v.Name.String()
+	case *ast.GenDecl:
+		return v.Tok.String() + " " + strings.Join(declNames(v), " ")
+	}
+	return ""
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	switch v := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(v.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(v.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(v.X)
+	case *ast.Ident:
+		return v.Name
+	}
+	return ""
+}
+
+func declNames(decl *ast.GenDecl) []string {
+	names := []string{}
+	for _, spec := range decl.Specs {
+		switch v := spec.(type) {
+		case *ast.ValueSpec:
+			for _, name := range v.Names {
+				names = append(names, name.String())
+			}
+		case *ast.TypeSpec:
+			names = append(names, v.Name.String())
+		case *ast.ImportSpec:
+			names = append(names, v.Path.Value)
+		}
+	}
+	return names
+}
+
+// declarationSpeech names a declaration, as in "function main" or
+// "constants small and large".
+func (gsp *goSpeaker) declarationSpeech(decl ast.Decl) string {
+	switch v := decl.(type) {
+	case *ast.FuncDecl:
+		return gsp.funcDeclSpeech(v)
+	case *ast.GenDecl:
+		if v.Tok == token.IMPORT {
+			return gsp.phrase("Changes imports", "Count", len(v.Specs))
+		}
+		names := []string{}
+		for _, name := range declNames(v) {
+			names = append(names, gsp.symbolToSpeech(name))
+		}
+		return gsp.phrase("Changes "+v.Tok.String(), "Count", len(names), "Names", names)
+	}
+	return ""
+}
+
+// SpeakChanges reads the declarations of the loaded file that are new or
+// differ from those in previous, an earlier version of the same file, and
+// names the ones that were removed. A declaration that no longer parses
+// is read with its syntax errors.
+func (gsp *goSpeaker) SpeakChanges(previous string) error {
+	if !gsp.isLoaded() || gsp.pkg != nil {
+		return ErrNothingLoaded
+	}
+	// The earlier version may not parse completely either
+	oldSet := token.NewFileSet()
+	oldFile, err := parser.ParseFile(oldSet, "previous", previous, parser.ParseComments)
+	if oldFile == nil {
+		return err
+	}
+	oldDecls, oldKeys := declarations(oldSet, oldFile, previous)
+	newDecls, newKeys := declarations(gsp.fileSet, gsp.file, gsp.fileBuffer)
+
+	defer gsp.SetRange(gsp.startLine, gsp.endLine)
+	events := []SpeechEvent{}
+	for _, key := range newKeys {
+		decl := newDecls[key]
+		old, existed := oldDecls[key]
+		if existed && old.source == decl.source {
+			continue
+		}
+		start := decl.decl.Pos()
+		if doc := declDoc(decl.decl); doc != nil {
+			start = doc.Pos()
+		}
+		end := gsp.fileSet.Position(decl.decl.End()).Line
+		if end < gsp.fileSet.Position(start).Line {
+			end = strings.Count(gsp.fileBuffer, "\n") + 1
+		}
+		gsp.SetRange(gsp.fileSet.Position(start).Line, end)
+		gsp.render()
+		body := gsp.events
+
+		message := "Changes added"
+		if existed {
+			message = "Changes changed"
+		}
+		gsp.events = nil
+		// Unreadable code has no name, and its body says it has a syntax error
+		if speech := gsp.declarationSpeech(decl.decl); speech != "" {
+			gsp.speakPhrase(message, "Declaration", speech)
+		}
+		events = append(append(events, gsp.events...), body...)
+	}
+
+	gsp.events = nil
+	for _, key := range oldKeys {
+		if _, ok := newDecls[key]; ok {
+			continue
+		}
+		if speech := gsp.declarationSpeech(oldDecls[key].decl); speech != "" {
+			gsp.speakPhrase("Changes removed", "Declaration", speech)
+		}
+	}
+	events = append(events, gsp.events...)
+
+	gsp.events = events
+	if len(gsp.events) == 0 {
+		gsp.speakPhrase("Changes none")
+	}
+	return gsp.speakBuffer()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"github.com/wutka/gospeak"
+	"github.com/wutka/gospeak/dictation"
+	"io/ioutil"
+	"os"
+)
+
+// runDictation prints the Go source described by each dictation file, or
+// by standard input when there are none.
+func runDictation(filenames []string, lexicon gospeak.Lexicon) {
+	parser := dictation.MakeParser()
+	parser.SetLexicon(lexicon)
+
+	if len(filenames) == 0 {
+		filenames = []string{"-"}
+	}
+	for _, filename := range filenames {
+		var speech []byte
+		var err error
+		if filename == "-" {
+			speech, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			speech, err = ioutil.ReadFile(filename)
+		}
+		if err != nil {
+			fmt.Printf("Unable to read %s: %+v\n", filename, err)
+			continue
+		}
+		source, err := parser.Source(string(speech))
+		if err != nil {
+			fmt.Printf("Unable to understand %s: %+v\n", filename, err)
+			continue
+		}
+		os.Stdout.Write(source)
+	}
+}
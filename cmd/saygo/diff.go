@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/wutka/gospeak"
+	"os/exec"
+	"path/filepath"
+)
+
+// runDiff speaks the changes to Go files between rev and the working tree.
+// Paths are the files or directories to limit the diff to.
+func runDiff(speaker gospeak.GoSpeaker, rev string, paths []string) []gospeak.SpeechEvent {
+	if len(paths) == 0 {
+		paths = []string{"*.go"}
+	}
+	args := append([]string{"diff", "--relative", "--no-color", "--no-ext-diff", "-U0", rev, "--"}, paths...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		fmt.Printf("Unable to run git diff: %+v\n", err)
+		return nil
+	}
+	diffs, err := gospeak.ParseDiff(bytes.NewReader(out))
+	if err != nil {
+		fmt.Printf("Unable to read git diff: %+v\n", err)
+		return nil
+	}
+
+	events := []gospeak.SpeechEvent{}
+	for _, diff := range diffs {
+		if !isGoFile(diff.Filename) && !isGoFile(diff.OldFilename) {
+			continue
+		}
+		oldSource := ""
+		if diff.OldFilename != "" {
+			// With --relative, paths are relative to the current directory
+			old, err := exec.Command("git", "show", rev+":./"+diff.OldFilename).Output()
+			if err != nil {
+				fmt.Printf("Unable to read %s at %s: %+v\n", diff.OldFilename, rev, err)
+			}
+			oldSource = string(old)
+		}
+		err = speaker.SpeakDiff(diff, oldSource)
+		if err != nil {
+			fmt.Printf("Unable to read changes to %s: %+v\n", diff.Filename, err)
+			continue
+		}
+		events = append(events, speaker.SpeechEvents()...)
+	}
+	return events
+}
+
+func isGoFile(filename string) bool {
+	return filepath.Ext(filename) == ".go"
+}
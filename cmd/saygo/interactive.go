@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/wutka/gospeak"
+	"os"
+	"strings"
+	"time"
+)
+
+const interactiveHelp = `Commands:
+  n, next       next statement
+  p, previous   previous statement
+  i, in         into the block of the current statement
+  o, out        out to the enclosing statement
+  r, repeat     repeat the current statement
+  w, where      where am I
+  s, stop       stop reading
+  pause         pause reading
+  resume        go on reading
+  q, quit       quit`
+
+func runInteractive(speaker gospeak.GoSpeaker, filename string, quiet bool) {
+	err := speaker.LoadFileErr(filename)
+	if err != nil {
+		fmt.Printf("Unable to read %s: %+v\n", filename, err)
+		return
+	}
+	nav, err := speaker.Navigator()
+	if err != nil {
+		fmt.Printf("Unable to navigate %s: %+v\n", filename, err)
+		return
+	}
+
+	// Speech is read in the background so that the next command can cut it
+	// short. Stop only reaches speech that has started, so it is repeated
+	// until the step is over.
+	var speaking chan struct{}
+	stop := func() {
+		if speaking == nil {
+			return
+		}
+		for {
+			speaker.Stop()
+			select {
+			case <-speaking:
+				speaking = nil
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	}
+	defer stop()
+
+	step := func(move func() error) {
+		if quiet {
+			if err := move(); err != nil {
+				fmt.Printf("Unable to speak: %+v\n", err)
+			}
+			printSpeech(speaker)
+			return
+		}
+		stop()
+		done := make(chan struct{})
+		speaking = done
+		go func() {
+			defer close(done)
+			if err := move(); err != nil && err != context.Canceled {
+				fmt.Printf("Unable to speak: %+v\n", err)
+			}
+		}()
+	}
+
+	step(nav.Repeat)
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("> ")
+		if !scanner.Scan() {
+			return
+		}
+		switch strings.TrimSpace(scanner.Text()) {
+		case "n", "next":
+			step(nav.Next)
+		case "p", "prev", "previous":
+			step(nav.Previous)
+		case "i", "in", "into":
+			step(nav.Into)
+		case "o", "out":
+			step(nav.Out)
+		case "r", "repeat", "":
+			step(nav.Repeat)
+		case "w", "where":
+			step(nav.Where)
+		case "s", "stop":
+			stop()
+		case "pause":
+			if err := speaker.Pause(); err != nil {
+				fmt.Printf("Unable to pause: %+v\n", err)
+			}
+		case "resume":
+			if err := speaker.Resume(); err != nil {
+				fmt.Printf("Unable to resume: %+v\n", err)
+			}
+		case "q", "quit", "exit":
+			return
+		default:
+			fmt.Println(interactiveHelp)
+		}
+	}
+}
+
+func printSpeech(speaker gospeak.GoSpeaker) {
+	phrases := []string{}
+	for _, e := range speaker.SpeechEvents() {
+		if !e.IsEarcon() {
+			phrases = append(phrases, e.Phrase)
+		}
+	}
+	fmt.Println(strings.Join(phrases, ", "))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"github.com/wutka/gospeak"
+	"github.com/wutka/gospeak/lsp"
+	"io/ioutil"
+	"os"
+)
+
+// runLSP serves the language server on stdin and stdout. Speech is spoken
+// aloud unless quiet is set, and requests asking for audio get a new file
+// with the given extension in the temp directory.
+func runLSP(makeSpeaker func(quiet bool, audioFile string) gospeak.GoSpeaker, quiet bool, audioExt string) {
+	server := lsp.MakeServer(func(audio bool) (gospeak.GoSpeaker, string, error) {
+		if !audio {
+			return makeSpeaker(quiet, ""), "", nil
+		}
+		f, err := ioutil.TempFile("", "gospeak-*."+audioExt)
+		if err != nil {
+			return nil, "", err
+		}
+		f.Close()
+		return makeSpeaker(false, f.Name()), f.Name(), nil
+	})
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Language server failed: %+v\n", err)
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,11 @@ import (
 	"flag"
 	"fmt"
 	"github.com/wutka/gospeak"
+	"go/build"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
 )
 
 func main() {
@@ -14,10 +19,147 @@ func main() {
 	outputFlag := flag.String("o", "", "Save speech to file")
 	startFlag := flag.Int("start", -1, "Start at line")
 	endFlag := flag.Int("end", -1, "End at line (inclusive)")
+	backendFlag := flag.String("backend", "say", "Speech backend to use (say, espeak-ng, ssml)")
+	voiceFlag := flag.String("voice", "", "Voice for the speech backend")
+	rateFlag := flag.Int("rate", -1, "Speaking rate in words per minute")
+	pitchFlag := flag.Int("pitch", -1, "Voice pitch (0-99, espeak-ng only)")
+	formatFlag := flag.String("format", "speech", "Output format (speech, json)")
+	outlineFlag := flag.Bool("outline", false, "Read only declarations, without bodies")
+	typesFlag := flag.Bool("types", false, "Type check the source and include types in the speech")
+	exportedFlag := flag.Bool("exported", false, "Read only the exported API")
+	interactiveFlag := flag.Bool("i", false, "Step through a file interactively")
+	errorsFlag := flag.Bool("errors", false, "Read only syntax errors and the lines around them")
+	commentsFlag := flag.String("comments", "off", "Read comments (off, doc, all)")
+	captionsFlag := flag.String("captions", "", "Also write vtt or srt captions next to the -o audio file")
+	diffFlag := flag.String("diff", "", "Read only the changes to Go files since a git revision")
+	earconsFlag := flag.String("earcons", "", "Play tones instead of words for block starts and ends (default, or a JSON file)")
+	lexiconFlag := flag.String("lexicon", "", "Pronunciation lexicon file (JSON), used in place of the default lexicons")
+	langFlag := flag.String("lang", "en", "Language to speak (en, es, de, or a JSON locale file)")
+	profileFlag := flag.String("profile", "", "Phrasing profile (terse, verbose, or a JSON profile file)")
+	dictateFlag := flag.Bool("dictate", false, "Print the Go source described by dictation files (speech scripts or plain text)")
+	watchFlag := flag.Bool("watch", false, "Read the declarations that change each time a file is saved")
 
 	flag.Parse()
 
-	speaker := gospeak.MakeGoSpeaker(*quietFlag, *verboseFlag, *skipImportsFlag, *outputFlag)
+	if *captionsFlag != "" && *outputFlag == "" {
+		fmt.Printf("Captions need an audio output file (-o)\n")
+		return
+	}
+
+	if *formatFlag != "speech" && *formatFlag != "json" {
+		fmt.Printf("Unknown output format %s\n", *formatFlag)
+		return
+	}
+	if *formatFlag == "json" {
+		*quietFlag = true
+	}
+
+	var locale gospeak.Locale
+	var err error
+	if strings.HasSuffix(*langFlag, ".json") {
+		locale, err = gospeak.LoadLocale(*langFlag)
+	} else {
+		locale, err = gospeak.BuiltinLocale(*langFlag)
+	}
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+
+	voice := gospeak.VoiceSettings{
+		Voice:    *voiceFlag,
+		Rate:     *rateFlag,
+		Pitch:    *pitchFlag,
+		Language: locale.Language(),
+	}
+	backend, err := gospeak.MakeSpeechBackend(*backendFlag, voice)
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+
+	commentMode, err := gospeak.ParseCommentMode(*commentsFlag)
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+
+	verbosity := gospeak.VerbosityFull
+	if *outlineFlag {
+		verbosity = gospeak.VerbosityOutline
+	}
+
+	var lexicon gospeak.Lexicon
+	if *lexiconFlag != "" {
+		lexicon, err = gospeak.LoadLexicon(*lexiconFlag)
+	} else {
+		lexicon, err = gospeak.LoadDefaultLexicon(argumentDir(flag.Arg(0)))
+	}
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+
+	if *dictateFlag {
+		runDictation(flag.Args(), lexicon)
+		return
+	}
+
+	var earcons gospeak.Earcons
+	if *earconsFlag == "default" {
+		earcons = gospeak.DefaultEarcons()
+	} else if *earconsFlag != "" {
+		earcons, err = gospeak.LoadEarcons(*earconsFlag)
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+	}
+
+	var profile gospeak.Profile
+	if strings.HasSuffix(*profileFlag, ".json") {
+		profile, err = gospeak.LoadProfile(*profileFlag)
+	} else if *profileFlag != "" {
+		profile, err = gospeak.BuiltinProfile(*profileFlag)
+	}
+	if err != nil {
+		fmt.Printf("%+v\n", err)
+		return
+	}
+	if !profile.AppliesTo(locale) {
+		fmt.Printf("Profile %s is worded in another language than %s\n", *profileFlag, *langFlag)
+		return
+	}
+
+	makeSpeaker := func(quiet bool, audioFile string) gospeak.GoSpeaker {
+		speaker := gospeak.MakeGoSpeaker(quiet, *verboseFlag, *skipImportsFlag, verbosity, audioFile, backend)
+		speaker.SetCommentMode(commentMode)
+		speaker.SetExportedOnly(*exportedFlag)
+		speaker.SetTypeChecked(*typesFlag)
+		speaker.SetLogger(log.New(os.Stderr, "", 0))
+		speaker.SetLexicon(lexicon)
+		speaker.SetEarcons(earcons)
+		speaker.SetCaptioned(*captionsFlag != "")
+		speaker.SetLocale(locale)
+		speaker.SetProfile(profile)
+		return speaker
+	}
+
+	if flag.NArg() == 1 && flag.Arg(0) == "lsp" {
+		audioExt := "wav"
+		switch *backendFlag {
+		case "say":
+			audioExt = "aiff"
+		case "ssml":
+			audioExt = "ssml"
+		}
+		// Without a file the ssml backend writes to standard output, which
+		// carries the protocol
+		runLSP(makeSpeaker, *quietFlag || *backendFlag == "ssml", audioExt)
+		return
+	}
+
+	speaker := makeSpeaker(*quietFlag, *outputFlag)
 	if *startFlag >= 0 && *endFlag >= 0 {
 		if *endFlag < *startFlag {
 			fmt.Printf("End line (%d) cannot be before start line (%d)\n", *endFlag, *startFlag)
@@ -27,11 +169,137 @@ func main() {
 
 	}
 
-	for _, filename := range flag.Args() {
-		if *functionNameFlag == "" {
-			speaker.SpeakGoFile(filename)
+	if *interactiveFlag {
+		if flag.NArg() != 1 {
+			fmt.Printf("Interactive mode reads exactly one file\n")
+			return
+		}
+		runInteractive(speaker, flag.Arg(0), *quietFlag)
+		return
+	}
+
+	if *watchFlag {
+		if flag.NArg() != 1 {
+			fmt.Printf("Watch mode reads exactly one file\n")
+			return
+		}
+		runWatch(speaker, flag.Arg(0), *quietFlag, *formatFlag == "json")
+		return
+	}
+
+	events := []gospeak.SpeechEvent{}
+	filenames := flag.Args()
+	if *diffFlag != "" {
+		// The arguments limit which paths the diff covers
+		events = runDiff(speaker, *diffFlag, filenames)
+		filenames = nil
+	}
+	audioFile := *outputFlag
+	for i, filename := range filenames {
+		if isPackage(filename) && (*errorsFlag || *functionNameFlag != "") {
+			fmt.Printf("Unable to read %s: -errors and -func read a single file, not a package\n", filename)
+			continue
+		}
+		if *outputFlag != "" && len(filenames) > 1 {
+			// Each file gets its own audio, and captions to go with it
+			audioFile = numberedFile(*outputFlag, i+1)
+			speaker = makeSpeaker(*quietFlag, audioFile)
+			if *startFlag >= 0 && *endFlag >= 0 {
+				speaker.SetRange(*startFlag, *endFlag)
+			}
+		}
+		if *lexiconFlag == "" && len(filenames) > 1 {
+			// Each file is read with the lexicon of its own project
+			lexicon, err = gospeak.LoadDefaultLexicon(argumentDir(filename))
+			if err != nil {
+				fmt.Printf("%+v\n", err)
+				continue
+			}
+			speaker.SetLexicon(lexicon)
+		}
+		if *errorsFlag {
+			// A file whose package clause is broken doesn't load, but its
+			// errors can still be read
+			err = speaker.LoadFileErr(filename)
+			if err == nil || len(speaker.ParseErrors()) > 0 {
+				err = speaker.SpeakErrors()
+			}
+		} else if isPackage(filename) {
+			err = speaker.SpeakGoPackage(filename)
+		} else if *functionNameFlag == "" {
+			err = speaker.SpeakGoFileErr(filename)
 		} else {
-			speaker.SpeakGoFunction(filename, *functionNameFlag)
+			err = speaker.SpeakGoFunctionErr(filename, *functionNameFlag)
+		}
+		if err != nil {
+			fmt.Printf("Unable to read %s: %+v\n", filename, err)
+			continue
+		}
+		events = append(events, speaker.SpeechEvents()...)
+
+		if *captionsFlag != "" {
+			writeCaptions(speaker, audioFile, gospeak.CaptionFormat(*captionsFlag))
+		}
+	}
+
+	if *formatFlag == "json" {
+		err = gospeak.WriteSpeechJSON(os.Stdout, events)
+		if err != nil {
+			fmt.Printf("Unable to write JSON: %+v\n", err)
 		}
 	}
 }
+
+func writeCaptions(speaker gospeak.GoSpeaker, audioFile string, format gospeak.CaptionFormat) {
+	captionFile := strings.TrimSuffix(audioFile, filepath.Ext(audioFile)) + "." + string(format)
+	f, err := os.Create(captionFile)
+	if err != nil {
+		fmt.Printf("Unable to create %s: %+v\n", captionFile, err)
+		return
+	}
+	defer f.Close()
+
+	err = speaker.WriteCaptions(f, format)
+	if err != nil {
+		fmt.Printf("Unable to write captions: %+v\n", err)
+	}
+}
+
+// numberedFile numbers a file name, so out.wav is out-2.wav for the second
+// file read.
+func numberedFile(filename string, n int) string {
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), n, ext)
+}
+
+// argumentDir returns the directory a command-line argument is in, so that
+// the lexicon of its project can be found: a directory itself, the
+// directory of a file or of an import path, and otherwise the current one.
+func argumentDir(arg string) string {
+	if arg == "" || arg == "lsp" {
+		return "."
+	}
+	if fi, err := os.Stat(arg); err == nil && fi.IsDir() {
+		return arg
+	}
+	if !strings.HasSuffix(arg, ".go") {
+		if pkg, err := build.Import(arg, ".", build.FindOnly); err == nil {
+			return pkg.Dir
+		}
+	}
+	return filepath.Dir(arg)
+}
+
+// isPackage reports whether a command-line argument names a package
+// directory or import path rather than a single Go file. Anything else,
+// including a file that doesn't exist, is read as a file.
+func isPackage(arg string) bool {
+	if fi, err := os.Stat(arg); err == nil {
+		return fi.IsDir()
+	}
+	if strings.HasSuffix(arg, ".go") {
+		return false
+	}
+	_, err := build.Import(arg, ".", build.FindOnly)
+	return err == nil
+}
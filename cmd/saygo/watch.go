@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/wutka/gospeak"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// watchInterval is how often a watched file is checked for a save.
+const watchInterval = 500 * time.Millisecond
+
+// runWatch reads the declarations of a file that change each time it is
+// saved, until saygo is interrupted. The file is polled rather than watched
+// with inotify so that it works the same on every system.
+func runWatch(speaker gospeak.GoSpeaker, filename string, quiet bool, jsonFormat bool) {
+	previous, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("Unable to read %s: %+v\n", filename, err)
+		return
+	}
+	var modTime time.Time
+	if fi, err := os.Stat(filename); err == nil {
+		modTime = fi.ModTime()
+	}
+	fmt.Printf("Watching %s, press Ctrl-C to stop\n", filename)
+
+	for {
+		time.Sleep(watchInterval)
+		// Editors that save by replacing the file leave it missing for a moment
+		fi, err := os.Stat(filename)
+		if err != nil || fi.ModTime().Equal(modTime) {
+			continue
+		}
+		modTime = fi.ModTime()
+		source, err := ioutil.ReadFile(filename)
+		if err != nil || bytes.Equal(source, previous) {
+			continue
+		}
+
+		err = speaker.LoadFileErr(filename)
+		if err == nil {
+			err = speaker.SpeakChanges(string(previous))
+		}
+		if err != nil {
+			// Keep the last version that parsed to compare the next save with
+			fmt.Printf("Unable to read %s: %+v\n", filename, err)
+			continue
+		}
+		previous = source
+
+		if jsonFormat {
+			if err = gospeak.WriteSpeechJSON(os.Stdout, speaker.SpeechEvents()); err != nil {
+				fmt.Printf("Unable to write JSON: %+v\n", err)
+			}
+		} else if quiet {
+			printSpeech(speaker)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gospeak
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+type CommentMode int
+
+const (
+	CommentsOff CommentMode = iota
+	CommentsDoc
+	CommentsAll
+)
+
+func ParseCommentMode(mode string) (CommentMode, error) {
+	switch mode {
+	case "", "off":
+		return CommentsOff, nil
+	case "doc":
+		return CommentsDoc, nil
+	case "all":
+		return CommentsAll, nil
+	}
+	return CommentsOff, fmt.Errorf("unknown comment mode %s (use off, doc or all)", mode)
+}
+
+func (gsp *goSpeaker) SetCommentMode(mode CommentMode) {
+	gsp.commentMode = mode
+}
+
+func (gsp *goSpeaker) resetComments() {
+	gsp.nextComment = 0
+	gsp.spokenComments = map[*ast.CommentGroup]bool{}
+}
+
+// speakDocComment reads the doc comment attached to a declaration, spec or
+// field. It must be called before the declaration itself is spoken.
+func (gsp *goSpeaker) speakDocComment(doc *ast.CommentGroup) {
+	if gsp.commentMode == CommentsOff || doc == nil {
+		return
+	}
+	gsp.speakCommentsBefore(doc.Pos())
+	gsp.speakCommentGroup(doc, "CommentGroup doc")
+}
+
+// speakLineComment reads the comment that trails a spec or field on the
+// same line.
+func (gsp *goSpeaker) speakLineComment(comment *ast.CommentGroup) {
+	if gsp.commentMode != CommentsAll || comment == nil {
+		return
+	}
+	gsp.speakCommentGroup(comment, "CommentGroup")
+}
+
+// speakCommentsBefore reads any comments in the file that end before pos and
+// haven't been read yet. Statement blocks call it before each statement so
+// that free-standing comments are heard where they appear.
+func (gsp *goSpeaker) speakCommentsBefore(pos token.Pos) {
+	if gsp.commentMode != CommentsAll || gsp.file == nil {
+		return
+	}
+	for gsp.nextComment < len(gsp.file.Comments) {
+		cg := gsp.file.Comments[gsp.nextComment]
+		if cg.End() > pos {
+			return
+		}
+		gsp.speakCommentGroup(cg, "CommentGroup")
+		gsp.nextComment++
+	}
+}
+
+func (gsp *goSpeaker) speakCommentGroup(cg *ast.CommentGroup, cue string) {
+	if gsp.spokenComments == nil {
+		gsp.resetComments()
+	}
+	if gsp.spokenComments[cg] {
+		return
+	}
+	gsp.spokenComments[cg] = true
+	if !gsp.isInRange(cg) {
+		return
+	}
+
+	gsp.enterNode(cg)
+	defer gsp.leaveNode()
+
+	gsp.speakPhrase(cue)
+	for _, line := range strings.Split(cg.Text(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			gsp.speak(strings.TrimSpace(line))
+		}
+	}
+}
+
+func (gsp *goSpeaker) fileEnd(file *ast.File) token.Pos {
+	tokFile := gsp.fileSet.File(file.Pos())
+	if tokFile == nil {
+		return file.End()
+	}
+	return token.Pos(tokFile.Base() + tokFile.Size())
+}
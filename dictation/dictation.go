@@ -0,0 +1,280 @@
+// Package dictation turns speech back into Go, so that code can be written
+// by voice. It understands the English phrasing gospeak reads code with,
+// such as "function add taking two parameters a and b all as int and
+// returning one value int", and prints the program it describes with
+// go/format.
+//
+// Dictation comes in one of two forms. A speech script, as written by
+// gospeak, ends each phrase with {pause}; each identifier is a phrase of its
+// own there, so identifiers may be spoken as several words. Plain text is
+// read a word at a time, with phrases broken at line ends and at commas,
+// semicolons and periods; there an identifier is said as one word, and
+// strings are written in double quotes.
+//
+// The phrasing leaves some things out that a Go program needs, so they are
+// chosen the way code is usually written: an assignment declares its
+// variables with := unless they are already in scope, a channel type is
+// bidirectional, a lone argument or an index is read as tightly as it can
+// be, as in "len of s minus one", and the arguments after a call that is
+// itself an argument, as in "f of g of x comma y", go to the inner call.
+package dictation
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/wutka/gospeak"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Parser reads dictated Go.
+type Parser struct {
+	pronunciations map[string]string
+	longest        int
+}
+
+// MakeParser returns a parser that knows gospeak's built-in pronunciations,
+// so that "fumt" is read as fmt.
+func MakeParser() *Parser {
+	p := &Parser{}
+	p.SetLexicon(nil)
+	return p
+}
+
+// SetLexicon adds the spoken forms of a pronunciation lexicon, as used by
+// gospeak, to the built-in ones.
+func (p *Parser) SetLexicon(lexicon gospeak.Lexicon) {
+	p.pronunciations = map[string]string{}
+	p.longest = 0
+	for symbol, speech := range gospeak.BuiltinPronunciations().Merge(lexicon) {
+		if !isIdentifier(symbol) {
+			continue
+		}
+		words := strings.Fields(strings.ToLower(speech))
+		p.pronunciations[strings.Join(words, " ")] = symbol
+		if len(words) > p.longest {
+			p.longest = len(words)
+		}
+	}
+}
+
+// ParseFile parses the dictation of a Go file. A dictation without a
+// package clause is parsed as package main, but the file's Package position
+// is left invalid so that callers can tell.
+func (p *Parser) ParseFile(speech string) (file *ast.File, err error) {
+	r := p.newReader(speech)
+	defer func() {
+		if e := recover(); e != nil {
+			perr, ok := e.(*Error)
+			if !ok {
+				panic(e)
+			}
+			err = perr
+		}
+	}()
+	file = r.parseFile()
+	return file, nil
+}
+
+// Source returns the formatted Go source of a dictation. Without a package
+// clause, only the declarations are returned, so a dictation with neither
+// gives empty source.
+func (p *Parser) Source(speech string) ([]byte, error) {
+	file, err := p.ParseFile(speech)
+	if err != nil {
+		return nil, err
+	}
+
+	// The parsed nodes have no positions, so the declarations are printed
+	// one at a time to keep blank lines between them. The printer doesn't
+	// check what it prints, so the result is parsed again, under a package
+	// clause if the dictation had none.
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\n", file.Name.Name)
+	for _, decl := range file.Decls {
+		if err = format.Node(&buf, token.NewFileSet(), decl); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n\n")
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if !file.Package.IsValid() {
+		_, decls, _ := bytes.Cut(source, []byte("\n"))
+		decls = bytes.TrimSpace(decls)
+		if len(decls) == 0 {
+			return []byte{}, nil
+		}
+		return append(decls, '\n'), nil
+	}
+	return source, nil
+}
+
+// Error reports where dictation could not be understood.
+type Error struct {
+	Word    int
+	Near    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Near == "" {
+		return fmt.Sprintf("at the end of the dictation: %s", e.Message)
+	}
+	return fmt.Sprintf("at word %d (%s): %s", e.Word+1, e.Near, e.Message)
+}
+
+type word struct {
+	text    string
+	lower   string
+	literal bool
+	escape  bool
+	phrase  int
+}
+
+const (
+	pauseMarker   = "{pause}"
+	literalMarker = "{literal}"
+	escapeMarker  = "{escape}"
+)
+
+var earconMarker = regexp.MustCompile(`\{earcon [^}]*\}`)
+
+// splitWords breaks dictation into words, numbering the phrase each word
+// belongs to.
+func splitWords(speech string) ([]word, bool) {
+	speech = earconMarker.ReplaceAllString(speech, "")
+	phrased := strings.Contains(speech, pauseMarker)
+	var phrases []string
+	if phrased {
+		phrases = strings.Split(speech, pauseMarker)
+	} else {
+		phrases = strings.Split(speech, "\n")
+	}
+
+	words := []word{}
+	phrase := 0
+	add := func(text string, literal bool) {
+		words = append(words, word{text: text, lower: strings.ToLower(text), literal: literal, phrase: phrase})
+	}
+	addPlain := func(s string) {
+		for _, field := range strings.Fields(s) {
+			trimmed := strings.TrimRight(field, ",;.")
+			if trimmed != "" {
+				add(trimmed, false)
+			}
+			if !phrased && trimmed != field {
+				phrase++
+			}
+		}
+	}
+
+	for _, text := range phrases {
+		phrase++
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if strings.HasPrefix(text, literalMarker) {
+			add(strings.TrimPrefix(text, literalMarker), true)
+			continue
+		}
+		if strings.HasPrefix(text, escapeMarker) {
+			// An escape is part of the string around it
+			add(strings.TrimSpace(strings.TrimPrefix(text, escapeMarker)), true)
+			words[len(words)-1].escape = true
+			continue
+		}
+		for {
+			start := strings.IndexByte(text, '"')
+			if start < 0 {
+				break
+			}
+			end := strings.IndexByte(text[start+1:], '"')
+			if end < 0 {
+				break
+			}
+			addPlain(text[:start])
+			add(text[start+1:start+1+end], true)
+			text = text[start+end+2:]
+		}
+		addPlain(text)
+	}
+	return words, phrased
+}
+
+func isIdentifier(symbol string) bool {
+	for _, ch := range symbol {
+		if !unicode.IsLetter(ch) && !unicode.IsDigit(ch) && ch != '_' {
+			return false
+		}
+	}
+	return symbol != ""
+}
+
+// joinWords puts an identifier back together from its spoken words, which
+// gospeak splits at case changes and underscores.
+func (p *Parser) joinWords(words []string) string {
+	var sb strings.Builder
+	for i := 0; i < len(words); {
+		n := p.longest
+		if n > len(words)-i {
+			n = len(words) - i
+		}
+		for ; n > 0; n-- {
+			if symbol, ok := p.pronunciations[strings.ToLower(strings.Join(words[i:i+n], " "))]; ok {
+				sb.WriteString(symbol)
+				break
+			}
+		}
+		if n > 0 {
+			i += n
+			continue
+		}
+		sb.WriteString(words[i])
+		i++
+	}
+	return sb.String()
+}
+
+var smallNumbers = map[string]uint64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+	"eight": 8, "nine": 9, "ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13,
+	"fourteen": 14, "fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18,
+	"nineteen": 19, "twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+	"seventy": 70, "eighty": 80, "ninety": 90,
+}
+
+var scales = map[string]uint64{
+	"thousand": 1e3, "million": 1e6, "billion": 1e9, "trillion": 1e12,
+	"quadrillion": 1e15, "quintillion": 1e18,
+}
+
+// numberWord reports whether a word can be part of a spoken number.
+func numberWord(w string) bool {
+	if _, ok := smallNumbers[w]; ok {
+		return true
+	}
+	if _, ok := scales[w]; ok {
+		return true
+	}
+	if w == "hundred" {
+		return true
+	}
+	if i := strings.IndexByte(w, '-'); i > 0 {
+		return numberWord(w[:i]) && numberWord(w[i+1:])
+	}
+	return isDigits(w)
+}
+
+func isDigits(w string) bool {
+	for _, ch := range w {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return w != ""
+}
@@ -0,0 +1,284 @@
+package dictation
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/wutka/gospeak"
+)
+
+// roundTripPrograms are spoken by gospeak and dictated back, which should
+// give the same program. Most of them are read in gospeak's own tests.
+var roundTripPrograms = []string{`package main
+
+import "fmt"
+
+func main() {
+	fmt.Printf("Hello World!\n")
+}
+`, `package main
+
+var foo int
+`, `package main
+
+var foo interface{}
+`, `package main
+
+func main() {
+	return
+}
+
+var greeting = "a < b"
+`, `package main
+
+import "fmt"
+
+type speaker struct {
+	quiet, verbose bool
+	name           string
+}
+
+func (s *speaker) Say(words string) error {
+	fmt.Println(words)
+	return nil
+}
+
+func Make(quiet bool, name string) (*speaker, error) {
+	return &speaker{quiet: quiet, name: name}, nil
+}
+`, `package main
+
+import "os"
+
+type user struct {
+	Name string
+}
+
+func main() {
+	count := len(os.Args)
+	file, _ := os.Open("x")
+	file.Close()
+	u := user{}
+	println(u.Name, count)
+}
+`, `package main
+
+const limit = 3
+
+type Point struct {
+	X, Y int
+}
+
+func helper() int {
+	return 1
+}
+
+func (p *Point) Sum() int {
+	return p.X + p.Y
+}
+`, `package main
+
+func main() {
+	var p *int
+	x := 1
+	x += 2
+	x++
+	f(x, p)
+	if x > 0 {
+		return
+	} else {
+		x = 2
+	}
+}
+`, `package main
+
+type Number interface {
+	~int | ~string
+}
+
+type List[T any] struct {
+	items []T
+}
+
+func Map[T any, U comparable](xs []T) *List[U] {
+	return nil
+}
+
+var m = Pair[int, string]{}
+`, `package shapes
+
+import (
+	"math"
+	"strings"
+)
+
+type Point struct {
+	X, Y float64
+	Name string ` + "`json:\"name\"`" + `
+}
+
+type Shape interface {
+	Area() float64
+	Scale(factor float64) Shape
+}
+
+const (
+	Small = iota
+	Medium
+	Large
+)
+
+const limit = 100
+
+var origin = Point{X: 0, Y: 0}
+
+func (p *Point) Distance(q Point) float64 {
+	dx := p.X - q.X
+	dy := p.Y - q.Y
+	return math.Pow(dx*dx+dy*dy, 0.5)
+}
+
+func names(points []Point) (result []string, count int) {
+	for i, p := range points {
+		if p.Name == "" {
+			continue
+		}
+		result = append(result, strings.ToUpper(p.Name))
+		count += i
+	}
+	return result, count
+}
+`, `package main
+
+func classify(n int, words map[string]int) string {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i * 2
+	}
+	for total > 10 {
+		total /= 2
+	}
+	switch {
+	case n < 0, n > 100:
+		return "out of range"
+	case n == 0:
+		fallthrough
+	default:
+		total--
+	}
+	switch x := words["a"]; x {
+	case 1, 2:
+		return "small"
+	}
+	if count, ok := words["b"]; ok && count >= 3 {
+		return "many"
+	} else if !ok {
+		return "none"
+	} else {
+		total = -count
+	}
+	return "some"
+}
+`, `package main
+
+import "sync"
+
+func worker(jobs chan int, done chan bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for {
+		select {
+		case job := <-jobs:
+			go func(n int) {
+				done <- n%2 == 0
+			}(job)
+		case <-done:
+			return
+		}
+	}
+}
+
+func describe(v interface{}) int {
+	switch t := v.(type) {
+	case int:
+		return t
+	case []byte:
+		return len(t[1:3])
+	}
+	values := []int{1, 2, 3}
+	values = append(values, values...)
+	grid := [3][2]float64{}
+	lookup := map[string][]int{"first": {1, 2}}
+	_ = lookup
+	return 0x1F + values[0] + int(grid[1][0])
+}
+`}
+
+func TestRoundTrip(t *testing.T) {
+	for i, prog := range roundTripPrograms {
+		speaker := gospeak.MakeGoSpeaker(true, false, false, gospeak.VerbosityFull, "", nil)
+		if err := speaker.SpeakGoStringErr(prog); err != nil {
+			t.Fatalf("program %d: %+v", i, err)
+		}
+		speech := gospeak.SpeechScript(speaker.SpeechEvents())
+
+		source, err := MakeParser().Source(speech)
+		if err != nil {
+			t.Errorf("program %d: %+v\n%s", i, err, speech)
+			continue
+		}
+		want, _ := format.Source([]byte(prog))
+		if string(source) != string(want) {
+			t.Errorf("program %d came back as\n%s\nfrom\n%s", i, source, speech)
+		}
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	source, err := MakeParser().Source(
+		"function add taking two parameters a and b all as int and returning one value int\n" +
+			"function body\n" +
+			"return a plus b times two\n" +
+			"end function add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "func add(a, b int) int {\n\treturn a + b*2\n}\n"
+	if string(source) != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, source)
+	}
+
+	source, err = MakeParser().Source(`var greeting of type string equals "hi there", ` +
+		`function main taking no parameters, function body, ` +
+		`let n equal length of greeting, if n is greater than three then print of greeting end if, ` +
+		`end function main`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{`var greeting string = "hi there"`, "n := len(greeting)",
+		"if n > 3 {", "print(greeting)"} {
+		if !strings.Contains(string(source), line) {
+			t.Errorf("expected %s in\n%s", line, source)
+		}
+	}
+}
+
+func TestDictationErrors(t *testing.T) {
+	_, err := MakeParser().Source("function main taking no parameters function body let x end function main")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if perr, ok := err.(*Error); !ok || perr.Near != "end" {
+		t.Errorf("expected an error near end, got %+v", err)
+	}
+
+	// Nothing dictated is no source, not a package clause
+	if source, err := MakeParser().Source(""); err != nil || len(source) != 0 {
+		t.Errorf("expected empty source for an empty dictation, got %q, %+v", source, err)
+	}
+
+	// Declarations alone are checked too
+	if _, err = MakeParser().Source("function add taking 2 parameters a b all as int"); err == nil {
+		t.Error("expected an error for a parameter without a type")
+	}
+}
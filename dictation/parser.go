@@ -0,0 +1,1706 @@
+package dictation
+
+import (
+	"fmt"
+	"github.com/wutka/gospeak"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// reader holds the state of one dictation as it is parsed. Errors are
+// raised as panics of *Error and recovered by ParseFile.
+type reader struct {
+	*Parser
+	words    []word
+	pos      int
+	phrased  bool
+	packages map[string]bool
+	scopes   []map[string]bool
+}
+
+func (p *Parser) newReader(speech string) *reader {
+	words, phrased := splitWords(speech)
+	return &reader{
+		Parser:   p,
+		words:    words,
+		phrased:  phrased,
+		packages: map[string]bool{},
+		scopes:   []map[string]bool{{}},
+	}
+}
+
+func (r *reader) errorf(format string, args ...interface{}) {
+	err := &Error{Word: r.pos, Message: fmt.Sprintf(format, args...)}
+	if r.pos < len(r.words) {
+		err.Near = r.words[r.pos].text
+	}
+	panic(err)
+}
+
+func (r *reader) atEnd() bool {
+	return r.pos >= len(r.words)
+}
+
+// phraseContinues reports whether the next word is in the same phrase as
+// the one before it.
+func (r *reader) phraseContinues() bool {
+	return r.pos > 0 && r.pos < len(r.words) && !r.words[r.pos].literal &&
+		r.words[r.pos].phrase == r.words[r.pos-1].phrase
+}
+
+// match returns how many words of a keyword phrase, such as "is less than",
+// come next. In a speech script a whole keyword must be a phrase of its own
+// unless prefix is set, for keywords such as "function" that share their
+// phrase with a name or a count.
+func (r *reader) match(phrase string, prefix bool) int {
+	parts := strings.Fields(phrase)
+	if r.pos+len(parts) > len(r.words) {
+		return 0
+	}
+	first := r.words[r.pos]
+	for i, part := range parts {
+		w := r.words[r.pos+i]
+		// gospeak's own phrasing is in lower case, so in a speech script a
+		// name such as "Default Earcons" is not read as a keyword
+		text := w.lower
+		if r.phrased {
+			text = w.text
+		}
+		if w.literal || text != part || w.phrase != first.phrase {
+			return 0
+		}
+	}
+	if r.phrased && !prefix {
+		if r.pos > 0 && r.words[r.pos-1].phrase == first.phrase && !r.words[r.pos-1].literal {
+			return 0
+		}
+		if end := r.pos + len(parts); end < len(r.words) && r.words[end].phrase == first.phrase {
+			return 0
+		}
+	}
+	return len(parts)
+}
+
+func (r *reader) at(phrase string) bool {
+	return r.match(phrase, false) > 0
+}
+
+func (r *reader) accept(phrase string) bool {
+	n := r.match(phrase, false)
+	r.pos += n
+	return n > 0
+}
+
+func (r *reader) atPrefix(phrase string) bool {
+	return r.match(phrase, true) > 0
+}
+
+func (r *reader) acceptPrefix(phrase string) bool {
+	n := r.match(phrase, true)
+	r.pos += n
+	return n > 0
+}
+
+func (r *reader) expect(phrase string) {
+	if !r.accept(phrase) {
+		r.errorf("expected %q", phrase)
+	}
+}
+
+func (r *reader) expectPrefix(phrase string) {
+	if !r.acceptPrefix(phrase) {
+		r.errorf("expected %q", phrase)
+	}
+}
+
+// try runs a parse that may not fit, putting the reader back if it doesn't.
+func (r *reader) try(parse func()) (ok bool) {
+	start := r.pos
+	defer func() {
+		if e := recover(); e != nil {
+			if _, isError := e.(*Error); !isError {
+				panic(e)
+			}
+			r.pos = start
+			ok = false
+		}
+	}()
+	parse()
+	return true
+}
+
+// reserved words end an identifier in plain text, and can't start one.
+var reserved = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		package imports declarations function body end taking returning with receiver
+		receivers parameter parameters value values field fields method methods having
+		all as is type constrained by and or let equal equals itself if initializer when
+		then else for ever while do loop range over key return also break continue goto
+		fallthrough at switch on case default select defer go increment decrement send
+		to channel label begin block of dot sub comma ellipsis containing call lambda
+		slice from start cap map pointer contents star ref not negative positive bitwise
+		exclusive receive received underlying does less than greater plus minus times
+		divided modulo shifted left right paren empty character hex octal binary point
+		imaginary interface struct embedding set tag variable element array var vars
+		constant constants no`) {
+		reserved[w] = true
+	}
+}
+
+// blockEnds end a list of statements.
+var blockEnds = []string{
+	"case", "default", "else", "end if", "end for loop", "end while loop", "end range",
+	"end switch", "end type switch", "end select", "end lambda", "end block",
+}
+
+// stopPhrases begin a statement or a declaration, so they can't start an
+// expression.
+var stopPhrases = []string{
+	"let", "if", "for", "for ever", "while", "range over", "return", "break", "continue",
+	"goto", "fallthrough", "switch", "select", "defer", "go", "increment", "decrement",
+	"send", "begin block", "var", "vars", "constant", "constants", "function body",
+}
+
+// stopPrefixes begin a phrase that goes on with a name.
+var stopPrefixes = []string{"type", "function", "label", "end function"}
+
+// connectors join the parts of a construct, so they can't start a name.
+var connectors = []string{
+	"and", "or", "of", "dot", "sub", "comma", "as", "as type", "all as", "with", "to",
+	"to end", "from", "equals", "equal", "containing", "then", "do", "when", "also",
+	"is", "element array of", "ellipsis", "with value", "with tag", "range body",
+	"on", "on type", "with initializer", "constrained by", "all constrained by",
+	"of type", "right paren",
+}
+
+// atBlockEnd reports whether a list of statements ends here.
+func (r *reader) atBlockEnd() bool {
+	if r.atEnd() || r.atPrefix("end function") {
+		return true
+	}
+	for _, phrase := range blockEnds {
+		if r.at(phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// atStop reports whether an expression can't start here.
+func (r *reader) atStop() bool {
+	if r.atBlockEnd() {
+		return true
+	}
+	for _, phrase := range stopPhrases {
+		if r.at(phrase) {
+			return true
+		}
+	}
+	return r.atStopPrefix() && !r.atNameLike()
+}
+
+// atStopPrefix reports whether a phrase such as "type Point" comes next.
+// Where an expression must come, it is read as a name.
+func (r *reader) atStopPrefix() bool {
+	for _, phrase := range stopPrefixes {
+		if n := r.match(phrase, true); n > 0 && r.continuesAfter(n) {
+			return true
+		}
+	}
+	return false
+}
+
+// continuesAfter reports whether the phrase goes on after its next n
+// words, as "function main" does.
+func (r *reader) continuesAfter(n int) bool {
+	next := r.pos + n
+	if next >= len(r.words) || r.words[next].literal || r.words[next].phrase != r.words[r.pos].phrase {
+		return false
+	}
+	return r.phrased || !reserved[r.words[next].lower]
+}
+
+// atNameLike tells a name such as typeChecked, spoken "type Checked", from
+// a phrase such as "type Point" by the phrase after it.
+func (r *reader) atNameLike() bool {
+	if !r.phrased {
+		return false
+	}
+	start := r.pos
+	defer func() { r.pos = start }()
+	phrase := r.words[r.pos].phrase
+	for !r.atEnd() && r.words[r.pos].phrase == phrase {
+		r.pos++
+	}
+	for _, follower := range []string{"as", "all as", "of type", "equal", "equals", "and",
+		"dot", "sub", "of", "as type", "comma"} {
+		if r.at(follower) {
+			return true
+		}
+	}
+	return false
+}
+
+// atName reports whether an identifier comes next.
+func (r *reader) atName() bool {
+	if r.atEnd() || r.words[r.pos].literal || r.atStop() {
+		return false
+	}
+	if !r.phrased {
+		return !reserved[r.words[r.pos].lower] || isTypeWord(r.words[r.pos].lower)
+	}
+	for _, phrase := range connectors {
+		if r.at(phrase) {
+			return false
+		}
+	}
+	if _, ok := binaryOp(r, false); ok {
+		return false
+	}
+	return true
+}
+
+func isTypeWord(w string) bool {
+	return w == "string"
+}
+
+// name reads an identifier: the rest of the phrase in a speech script, or
+// the words up to the next reserved word in plain text.
+func (r *reader) name() string {
+	if r.atEnd() || r.words[r.pos].literal {
+		r.errorf("expected a name")
+	}
+	words := []string{r.words[r.pos].text}
+	r.pos++
+	for r.phraseContinues() && (r.phrased || !reserved[r.words[r.pos].lower]) {
+		words = append(words, r.words[r.pos].text)
+		r.pos++
+	}
+	name := r.joinWords(words)
+	if name == "." {
+		return name
+	}
+	if !isIdentifier(name) {
+		r.pos--
+		r.errorf("%s is not a name", name)
+	}
+	return name
+}
+
+func (r *reader) ident() *ast.Ident {
+	return ast.NewIdent(r.name())
+}
+
+// skipName passes over a name repeated at the end of a block, as in "end
+// function main".
+func (r *reader) skipName() {
+	if r.phraseContinues() {
+		r.name()
+	}
+}
+
+// number reads a number said in words or digits, within one phrase.
+func (r *reader) number() (uint64, bool) {
+	if r.atEnd() || r.words[r.pos].literal {
+		return 0, false
+	}
+	if w := r.words[r.pos].lower; isDigits(w) {
+		n, err := strconv.ParseUint(w, 10, 64)
+		if err != nil {
+			r.errorf("%s is too large", w)
+		}
+		r.pos++
+		return n, true
+	}
+
+	var total, current uint64
+	found := false
+	phrase := r.words[r.pos].phrase
+	for !r.atEnd() && !r.words[r.pos].literal && r.words[r.pos].phrase == phrase {
+		w := r.words[r.pos].lower
+		if !numberWord(w) || isDigits(w) {
+			break
+		}
+		fits := true
+		for _, part := range strings.Split(w, "-") {
+			if n, ok := smallNumbers[part]; ok {
+				if (n < 10 && current%10 != 0) || (n >= 10 && current%100 != 0) {
+					fits = false
+					break
+				}
+				current += n
+			} else if part == "hundred" {
+				if current == 0 {
+					current = 1
+				}
+				current *= 100
+			} else {
+				if current == 0 {
+					current = 1
+				}
+				total += current * scales[part]
+				current = 0
+			}
+		}
+		if !fits {
+			break
+		}
+		found = true
+		r.pos++
+	}
+	return total + current, found
+}
+
+// count reads the count before a noun, as in "no parameters" or "2
+// values". It returns -1 when no count was said.
+func (r *reader) count() int {
+	if r.acceptPrefix("no") {
+		return 0
+	}
+	if n, ok := r.number(); ok {
+		return int(n)
+	}
+	return -1
+}
+
+func (r *reader) acceptNoun(nouns ...string) bool {
+	for _, noun := range nouns {
+		if r.acceptPrefix(noun) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reader) pushScope() {
+	r.scopes = append(r.scopes, map[string]bool{})
+}
+
+func (r *reader) popScope() {
+	r.scopes = r.scopes[:len(r.scopes)-1]
+}
+
+func (r *reader) declare(names ...*ast.Ident) {
+	for _, name := range names {
+		r.scopes[len(r.scopes)-1][name.Name] = true
+	}
+}
+
+func (r *reader) declareFields(lists ...*ast.FieldList) {
+	for _, list := range lists {
+		if list != nil {
+			for _, field := range list.List {
+				r.declare(field.Names...)
+			}
+		}
+	}
+}
+
+func (r *reader) declared(name string) bool {
+	for _, scope := range r.scopes {
+		if scope[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// assignToken chooses between := and = for an assignment, which are spoken
+// alike: := when it names a variable that isn't in scope yet.
+func (r *reader) assignToken(lhs ...ast.Expr) token.Token {
+	idents := []*ast.Ident{}
+	fresh := false
+	for _, expr := range lhs {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			return token.ASSIGN
+		}
+		if ident.Name != "_" && !r.declared(ident.Name) {
+			fresh = true
+		}
+		idents = append(idents, ident)
+	}
+	if !fresh {
+		return token.ASSIGN
+	}
+	r.declare(idents...)
+	return token.DEFINE
+}
+
+func (r *reader) parseFile() *ast.File {
+	file := &ast.File{Name: ast.NewIdent("main")}
+	if r.acceptPrefix("package") {
+		file.Package = 1
+		file.Name = r.ident()
+	}
+	if r.accept("imports") {
+		decl := &ast.GenDecl{Tok: token.IMPORT}
+		for !r.atEnd() && r.words[r.pos].literal {
+			spec := r.importSpec()
+			decl.Specs = append(decl.Specs, spec)
+			file.Imports = append(file.Imports, spec)
+		}
+		if len(decl.Specs) > 1 {
+			decl.Lparen, decl.Rparen = 1, 1
+		}
+		file.Decls = append(file.Decls, decl)
+	}
+	r.accept("declarations")
+	for !r.atEnd() {
+		file.Decls = append(file.Decls, r.decl())
+	}
+	return file
+}
+
+func (r *reader) importSpec() *ast.ImportSpec {
+	path := r.importPath(r.words[r.pos].text)
+	r.pos++
+	spec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(path)}}
+	name := path[strings.LastIndex(path, "/")+1:]
+	if r.acceptPrefix("as") {
+		if r.acceptPrefix("dot") {
+			spec.Name = ast.NewIdent(".")
+		} else {
+			spec.Name = r.ident()
+		}
+		name = spec.Name.Name
+	}
+	r.packages[name] = true
+	r.declare(ast.NewIdent(name))
+	return spec
+}
+
+var pathSeparators = map[string]string{"slash": "/", "dot": "."}
+
+// importPath puts an import path back together from its spoken words.
+func (r *reader) importPath(speech string) string {
+	var sb strings.Builder
+	words := []string{}
+	flush := func() {
+		sb.WriteString(r.joinWords(words))
+		words = words[:0]
+	}
+	for _, w := range strings.Fields(speech) {
+		if sep, ok := pathSeparators[strings.ToLower(w)]; ok {
+			flush()
+			sb.WriteString(sep)
+		} else {
+			words = append(words, w)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+func (r *reader) decl() ast.Decl {
+	switch {
+	case r.atPrefix("function"):
+		return r.funcDecl()
+	case r.atPrefix("type"):
+		return r.typeDecl()
+	case r.at("constant") || r.at("constants"):
+		return r.valueDecl(token.CONST, "constant", "constants")
+	case r.at("var") || r.at("vars"):
+		return r.valueDecl(token.VAR, "var", "vars")
+	}
+	r.errorf("expected a declaration")
+	return nil
+}
+
+func (r *reader) funcDecl() *ast.FuncDecl {
+	r.expectPrefix("function")
+	decl := &ast.FuncDecl{Name: r.ident(), Type: &ast.FuncType{}}
+	r.declare(decl.Name)
+	r.pushScope()
+	defer r.popScope()
+
+	decl.Type.TypeParams = r.typeParams()
+	decl.Recv = r.fieldList("with", "receiver", "receivers")
+	r.signature(decl.Type)
+	r.declareFields(decl.Recv, decl.Type.Params, decl.Type.Results)
+	decl.Body = &ast.BlockStmt{}
+	if r.accept("function body") {
+		decl.Body.List = r.stmtList()
+		r.expectPrefix("end function")
+		r.skipName()
+	}
+	if decl.Recv != nil {
+		// A receiver can't be declared in a scope of its own, but it was
+		// declared along with the parameters
+		decl.Recv.Opening, decl.Recv.Closing = 1, 1
+	}
+	return decl
+}
+
+// signature reads the parameters and results of a function.
+func (r *reader) signature(ft *ast.FuncType) {
+	ft.Params = r.fieldList("taking", "parameter", "parameters")
+	if ft.Params == nil {
+		ft.Params = &ast.FieldList{}
+	}
+	ft.Results = r.fieldList("and returning", "value", "values")
+	if ft.Results == nil {
+		ft.Results = r.fieldList("returning", "value", "values")
+	}
+	if ft.Results != nil && len(ft.Results.List) == 0 {
+		ft.Results = nil
+	}
+}
+
+// fieldList reads a list of fields introduced by a phrase and a count, as
+// in "taking 2 parameters a b all as int". Without a count, fields are read
+// for as long as they come.
+func (r *reader) fieldList(intro string, nouns ...string) *ast.FieldList {
+	start := r.pos
+	if !r.acceptPrefix(intro) {
+		return nil
+	}
+	n := r.count()
+	if !r.acceptNoun(nouns...) && n >= 0 {
+		r.pos = start
+		return nil
+	}
+	if n < 0 && !r.atName() && !r.at("as") {
+		r.pos = start
+		return nil
+	}
+
+	list := &ast.FieldList{}
+	for got := 0; n < 0 || got < n; {
+		if n < 0 && !r.atName() && !r.at("as") {
+			break
+		}
+		field := r.field()
+		list.List = append(list.List, field)
+		if len(field.Names) == 0 {
+			got++
+		} else {
+			got += len(field.Names)
+		}
+		if (n < 0 || got < n) && r.at("and") && !r.atAfter("and", "returning") {
+			r.accept("and")
+		}
+	}
+	return list
+}
+
+// atAfterKeyed reports whether the next element of a composite literal
+// with keys comes after a phrase. It ends a list that came before it, as
+// the arguments of a call.
+func (r *reader) atAfterKeyed(phrase string) bool {
+	start := r.pos
+	defer func() { r.pos = start }()
+	return r.accept(phrase) && r.accept("key") && r.try(func() {
+		r.element()
+		r.expect("with value")
+	})
+}
+
+// atAfter reports whether a phrase comes after another.
+func (r *reader) atAfter(first, second string) bool {
+	start := r.pos
+	defer func() { r.pos = start }()
+	return r.acceptPrefix(first) && r.atPrefix(second)
+}
+
+// field reads the names of a field and its type. Without "as", the words
+// are the type of a field without names.
+func (r *reader) field() *ast.Field {
+	field := &ast.Field{}
+	start := r.pos
+	names := []*ast.Ident{}
+	for r.atName() || r.atFieldName() {
+		names = append(names, r.ident())
+		if r.at("and") && !r.atAfter("and", "returning") {
+			r.accept("and")
+		}
+	}
+	if r.accept("all as") || r.accept("as") {
+		// "returning one value as int" has no names, and a result without
+		// names needs no parentheses
+		if len(names) > 0 {
+			field.Names = names
+		}
+	} else {
+		r.pos = start
+	}
+	field.Type = r.parseType(true)
+	if r.accept("with tag") {
+		field.Tag = r.stringLit()
+	}
+	return field
+}
+
+// atFieldName reports whether a name that is also a connector, such as
+// sub, is the last name of a field.
+func (r *reader) atFieldName() bool {
+	if !r.phrased || r.atEnd() || r.words[r.pos].literal {
+		return false
+	}
+	start := r.pos
+	defer func() { r.pos = start }()
+	phrase := r.words[r.pos].phrase
+	for !r.atEnd() && r.words[r.pos].phrase == phrase {
+		r.pos++
+	}
+	return r.at("as") || r.at("all as")
+}
+
+func (r *reader) typeParams() *ast.FieldList {
+	start := r.pos
+	if !r.acceptPrefix("with") {
+		return nil
+	}
+	r.count()
+	if !r.acceptNoun("type parameter", "type parameters") {
+		r.pos = start
+		return nil
+	}
+	list := &ast.FieldList{Opening: 1, Closing: 1}
+	for {
+		field := &ast.Field{}
+		for r.atName() {
+			field.Names = append(field.Names, r.ident())
+		}
+		if !r.accept("all constrained by") {
+			r.expect("constrained by")
+		}
+		field.Type = r.constraint()
+		list.List = append(list.List, field)
+		r.declare(field.Names...)
+		if !r.at("and") || r.atAfter("and", "returning") {
+			return list
+		}
+		r.accept("and")
+	}
+}
+
+// constraint reads a type constraint, which may be a union of terms.
+func (r *reader) constraint() ast.Expr {
+	x := r.parseType(true)
+	for r.accept("or") {
+		x = &ast.BinaryExpr{X: x, Op: token.OR, Y: r.parseType(true)}
+	}
+	return x
+}
+
+func (r *reader) typeDecl() *ast.GenDecl {
+	r.expectPrefix("type")
+	spec := &ast.TypeSpec{Name: r.ident()}
+	r.declare(spec.Name)
+	spec.TypeParams = r.typeParams()
+	r.expect("is")
+	spec.Type = r.parseType(true)
+	return &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{spec}}
+}
+
+// valueDecl reads a constant or variable declaration. Constants that
+// repeat the value before them, as with iota, are kept in one group with
+// it.
+func (r *reader) valueDecl(tok token.Token, one, many string) *ast.GenDecl {
+	if !r.accept(one) {
+		r.expect(many)
+	}
+	decl := &ast.GenDecl{Tok: tok, Specs: []ast.Spec{r.valueSpec()}}
+	for tok == token.CONST && r.atRepeatedConstant(one, many) {
+		r.accept(one)
+		r.accept(many)
+		decl.Specs = append(decl.Specs, r.valueSpec())
+		decl.Lparen, decl.Rparen = 1, 1
+	}
+	return decl
+}
+
+// atRepeatedConstant reports whether a constant without a value of its own
+// comes next.
+func (r *reader) atRepeatedConstant(one, many string) bool {
+	start := r.pos
+	defer func() { r.pos = start }()
+	if !r.accept(one) && !r.accept(many) {
+		return false
+	}
+	return r.try(func() {
+		spec := r.valueSpec()
+		if len(spec.Values) > 0 || spec.Type != nil {
+			r.errorf("constant has a value")
+		}
+	})
+}
+
+// valueSpec reads names, each followed by its type and value, as in "a of
+// type int equals one b of type int equals two".
+func (r *reader) valueSpec() *ast.ValueSpec {
+	spec := &ast.ValueSpec{}
+	for {
+		spec.Names = append(spec.Names, r.ident())
+		r.accept("of type")
+		if r.atTypeStart() {
+			typ := r.parseType(true)
+			if spec.Type == nil {
+				spec.Type = typ
+			}
+		}
+		if r.accept("equals") {
+			spec.Values = append(spec.Values, r.parseExpr())
+		}
+		if !r.atNextValueName() {
+			break
+		}
+	}
+	r.declare(spec.Names...)
+	return spec
+}
+
+// atNextValueName reports whether another name of the same declaration
+// comes next.
+func (r *reader) atNextValueName() bool {
+	if !r.atName() {
+		return false
+	}
+	start := r.pos
+	defer func() { r.pos = start }()
+	r.name()
+	return r.at("of type") || r.at("equals")
+}
+
+// atTypeStart reports whether a type comes next.
+func (r *reader) atTypeStart() bool {
+	if r.atName() {
+		return true
+	}
+	for _, phrase := range []string{"pointer to", "contents of", "star", "slice of", "map",
+		"function", "interface", "struct", "empty interface", "empty struct",
+		"send to channel", "received from channel", "left paren", "underlying type"} {
+		if r.at(phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *reader) stmtList() []ast.Stmt {
+	r.pushScope()
+	defer r.popScope()
+	stmts := []ast.Stmt{}
+	for !r.atBlockEnd() {
+		stmts = append(stmts, r.stmt())
+	}
+	return stmts
+}
+
+func (r *reader) block(end ...string) *ast.BlockStmt {
+	block := &ast.BlockStmt{List: r.stmtList()}
+	for _, e := range end {
+		if r.accept(e) {
+			return block
+		}
+	}
+	r.errorf("expected %q", end[0])
+	return nil
+}
+
+func (r *reader) stmt() ast.Stmt {
+	switch {
+	case r.accept("let"):
+		return r.assignStmt()
+	case r.accept("if"):
+		return r.ifStmt()
+	case r.accept("for ever"):
+		r.expect("do")
+		return &ast.ForStmt{Body: r.block("end for loop", "end while loop")}
+	case r.accept("while"):
+		loop := &ast.ForStmt{Cond: r.parseExpr()}
+		r.expect("do")
+		loop.Body = r.block("end while loop", "end for loop")
+		return loop
+	case r.accept("for"):
+		return r.forStmt()
+	case r.accept("range over"):
+		return r.rangeStmt()
+	case r.accept("return"):
+		ret := &ast.ReturnStmt{}
+		if !r.atStop() {
+			ret.Results = append(ret.Results, r.parseExpr())
+			for r.accept("also") {
+				ret.Results = append(ret.Results, r.parseExpr())
+			}
+		}
+		return ret
+	case r.at("break") || r.at("continue") || r.at("goto") || r.at("fallthrough"):
+		branch := &ast.BranchStmt{Tok: branchTokens[r.words[r.pos].lower]}
+		r.pos++
+		if r.accept("at") {
+			branch.Label = r.ident()
+		}
+		return branch
+	case r.accept("switch"):
+		return r.switchStmt()
+	case r.accept("select"):
+		sel := &ast.SelectStmt{Body: &ast.BlockStmt{}}
+		for r.at("case") || r.at("default") {
+			clause := &ast.CommClause{}
+			r.pushScope()
+			if r.accept("case") {
+				clause.Comm = r.stmt()
+			} else {
+				r.expect("default")
+			}
+			clause.Body = r.stmtList()
+			r.popScope()
+			sel.Body.List = append(sel.Body.List, clause)
+		}
+		r.expect("end select")
+		return sel
+	case r.accept("defer"):
+		return &ast.DeferStmt{Call: r.callExpr()}
+	case r.accept("go"):
+		return &ast.GoStmt{Call: r.callExpr()}
+	case r.accept("increment"):
+		return &ast.IncDecStmt{X: r.parseUnary(), Tok: token.INC}
+	case r.accept("decrement"):
+		return &ast.IncDecStmt{X: r.parseUnary(), Tok: token.DEC}
+	case r.accept("send"):
+		send := &ast.SendStmt{Value: r.parseExpr()}
+		r.expect("to channel")
+		send.Chan = r.parseExpr()
+		return send
+	case r.atStopPrefix() && !r.atNameLike() && r.acceptPrefix("label"):
+		return &ast.LabeledStmt{Label: r.ident(), Stmt: r.stmt()}
+	case r.accept("begin block"):
+		return r.block("end block")
+	case r.atStopPrefix() && !r.atNameLike() && r.atPrefix("type"),
+		r.at("constant") || r.at("constants") || r.at("var") || r.at("vars"):
+		return &ast.DeclStmt{Decl: r.decl()}
+	}
+	return &ast.ExprStmt{X: r.parseExpr()}
+}
+
+var branchTokens = map[string]token.Token{
+	"break": token.BREAK, "continue": token.CONTINUE, "goto": token.GOTO,
+	"fallthrough": token.FALLTHROUGH,
+}
+
+func (r *reader) callExpr() *ast.CallExpr {
+	expr := r.parseExpr()
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		r.errorf("expected a function call")
+	}
+	return call
+}
+
+var assignTokens = map[token.Token]token.Token{
+	token.ADD: token.ADD_ASSIGN, token.SUB: token.SUB_ASSIGN, token.MUL: token.MUL_ASSIGN,
+	token.QUO: token.QUO_ASSIGN, token.REM: token.REM_ASSIGN, token.AND: token.AND_ASSIGN,
+	token.OR: token.OR_ASSIGN, token.XOR: token.XOR_ASSIGN, token.SHL: token.SHL_ASSIGN,
+	token.SHR: token.SHR_ASSIGN, token.AND_NOT: token.AND_NOT_ASSIGN,
+}
+
+// assignStmt reads an assignment after "let". Several variables are
+// assigned either all at once, as in "let a and b equal f", or one after
+// another, as in "let a equal one b equal two".
+func (r *reader) assignStmt() ast.Stmt {
+	assign := &ast.AssignStmt{Lhs: []ast.Expr{r.parseUnary()}}
+	for r.accept("and") {
+		assign.Lhs = append(assign.Lhs, r.parseUnary())
+	}
+	if r.acceptPrefix("equal itself") {
+		op, ok := binaryOp(r, true)
+		if !ok {
+			r.errorf("expected an operator")
+		}
+		assign.Tok = assignTokens[op]
+		if assign.Tok == token.ILLEGAL {
+			r.errorf("%s can't be used in an assignment", op)
+		}
+		assign.Rhs = []ast.Expr{r.parseExpr()}
+		return assign
+	}
+	r.expect("equal")
+	assign.Rhs = []ast.Expr{r.parseExpr()}
+
+	if len(assign.Lhs) == 1 {
+		for !r.atStop() {
+			var lhs, rhs ast.Expr
+			if !r.try(func() {
+				lhs = r.parseUnary()
+				r.expect("equal")
+				rhs = r.parseExpr()
+			}) {
+				break
+			}
+			assign.Lhs = append(assign.Lhs, lhs)
+			assign.Rhs = append(assign.Rhs, rhs)
+		}
+	}
+	assign.Tok = r.assignToken(assign.Lhs...)
+	return assign
+}
+
+func (r *reader) ifStmt() *ast.IfStmt {
+	r.pushScope()
+	defer r.popScope()
+	stmt := &ast.IfStmt{}
+	if r.accept("with initializer") {
+		stmt.Init = r.stmt()
+		r.expect("when")
+	}
+	stmt.Cond = r.parseExpr()
+	r.expect("then")
+	stmt.Body = &ast.BlockStmt{List: r.stmtList()}
+	if r.accept("else") {
+		if r.accept("if") {
+			stmt.Else = r.ifStmt()
+		} else {
+			stmt.Else = r.block("end if")
+		}
+	} else {
+		r.expect("end if")
+	}
+	return stmt
+}
+
+func (r *reader) forStmt() *ast.ForStmt {
+	r.pushScope()
+	defer r.popScope()
+	loop := &ast.ForStmt{}
+	var first, second ast.Stmt
+	if !r.at("while") && !r.at("do") {
+		first = r.stmt()
+	}
+	if r.accept("while") {
+		loop.Cond = r.parseExpr()
+	}
+	if !r.at("do") {
+		second = r.stmt()
+	}
+	loop.Init, loop.Post = first, second
+	if second == nil && loop.Cond == nil && isPostStmt(first) {
+		loop.Init, loop.Post = nil, first
+	}
+	r.expect("do")
+	loop.Body = r.block("end for loop", "end while loop")
+	return loop
+}
+
+// isPostStmt reports whether a statement looks like the post statement of
+// a for loop rather than its initializer.
+func isPostStmt(stmt ast.Stmt) bool {
+	switch s := stmt.(type) {
+	case *ast.IncDecStmt:
+		return true
+	case *ast.AssignStmt:
+		return s.Tok != token.DEFINE && s.Tok != token.ASSIGN
+	}
+	return false
+}
+
+func (r *reader) rangeStmt() *ast.RangeStmt {
+	stmt := &ast.RangeStmt{X: r.parseExpr()}
+	r.pushScope()
+	defer r.popScope()
+	if r.accept("with") {
+		if r.accept("key") {
+			stmt.Key = r.parseUnary()
+			r.accept("and")
+		}
+		if r.accept("value") {
+			stmt.Value = r.parseUnary()
+		}
+	}
+	if stmt.Key != nil {
+		lhs := []ast.Expr{stmt.Key}
+		if stmt.Value != nil {
+			lhs = append(lhs, stmt.Value)
+		}
+		stmt.Tok = r.assignToken(lhs...)
+	}
+	r.expect("range body")
+	stmt.Body = r.block("end range")
+	return stmt
+}
+
+func (r *reader) switchStmt() ast.Stmt {
+	r.pushScope()
+	defer r.popScope()
+	var init ast.Stmt
+	if r.accept("with initializer") {
+		init = r.stmt()
+	}
+	if r.accept("on type") {
+		stmt := &ast.TypeSwitchStmt{Init: init, Assign: r.stmt()}
+		if assign, ok := stmt.Assign.(*ast.AssignStmt); ok {
+			assign.Tok = token.DEFINE
+		}
+		stmt.Body = r.caseClauses()
+		r.expect("end type switch")
+		return stmt
+	}
+	stmt := &ast.SwitchStmt{Init: init}
+	if r.accept("on") {
+		stmt.Tag = r.parseExpr()
+	}
+	stmt.Body = r.caseClauses()
+	r.expect("end switch")
+	return stmt
+}
+
+func (r *reader) caseClauses() *ast.BlockStmt {
+	body := &ast.BlockStmt{}
+	for r.at("case") || r.at("default") {
+		clause := &ast.CaseClause{}
+		if r.accept("case") {
+			// or separates the cases, so only operators that bind more
+			// tightly than || are read here
+			clause.List = append(clause.List, r.parseBinary(token.LAND.Precedence()))
+			for r.accept("or") {
+				clause.List = append(clause.List, r.parseBinary(token.LAND.Precedence()))
+			}
+		} else {
+			r.expect("default")
+		}
+		clause.Body = r.stmtList()
+		body.List = append(body.List, clause)
+	}
+	return body
+}
+
+// binaryOps are the spoken binary operators, longest first where one
+// begins another.
+var binaryOps = []struct {
+	phrase string
+	op     token.Token
+}{
+	{"is less than or equal to", token.LEQ},
+	{"is greater than or equal to", token.GEQ},
+	{"is less than", token.LSS},
+	{"is greater than", token.GTR},
+	{"does not equal", token.NEQ},
+	{"equals", token.EQL},
+	{"bitwise and not", token.AND_NOT},
+	{"bitwise and", token.AND},
+	{"bitwise or", token.OR},
+	{"exclusive or", token.XOR},
+	{"or", token.LOR},
+	{"and", token.LAND},
+	{"plus", token.ADD},
+	{"minus", token.SUB},
+	{"times", token.MUL},
+	{"divided by", token.QUO},
+	{"modulo", token.REM},
+	{"shifted left by", token.SHL},
+	{"shifted right by", token.SHR},
+}
+
+// binaryOp reads a binary operator, leaving the reader where it was if
+// there isn't one.
+func binaryOp(r *reader, prefix bool) (token.Token, bool) {
+	for _, b := range binaryOps {
+		if n := r.match(b.phrase, prefix); n > 0 {
+			r.pos += n
+			return b.op, true
+		}
+	}
+	return token.ILLEGAL, false
+}
+
+func (r *reader) parseExpr() ast.Expr {
+	return r.parseBinary(token.LowestPrec + 1)
+}
+
+// parseBinary reads operators of at least the given precedence, following
+// Go's rules, since speech has no parentheses but those that were written.
+func (r *reader) parseBinary(prec int) ast.Expr {
+	x := r.parseUnary()
+	for {
+		start := r.pos
+		op, ok := binaryOp(r, false)
+		if !ok {
+			return x
+		}
+		if op.Precedence() < prec {
+			r.pos = start
+			return x
+		}
+		x = &ast.BinaryExpr{X: x, Op: op, Y: r.parseBinary(op.Precedence() + 1)}
+	}
+}
+
+var unaryOps = []struct {
+	phrase string
+	op     token.Token
+}{
+	{"not", token.NOT},
+	{"negative", token.SUB},
+	{"positive", token.ADD},
+	{"bitwise not", token.XOR},
+	{"ref", token.AND},
+	{"receive from channel", token.ARROW},
+	{"underlying type", token.TILDE},
+}
+
+func (r *reader) parseUnary() ast.Expr {
+	for _, u := range unaryOps {
+		if r.accept(u.phrase) {
+			return &ast.UnaryExpr{Op: u.op, X: r.parseUnary()}
+		}
+	}
+	if r.accept("contents of") || r.accept("star") || r.accept("pointer to") {
+		return &ast.StarExpr{X: r.parseUnary()}
+	}
+	return r.parsePrimary()
+}
+
+func (r *reader) parsePrimary() ast.Expr {
+	x := r.parseOperand()
+	for {
+		switch {
+		case r.accept("dot"):
+			x = r.selector(x)
+		case r.accept("sub"):
+			x = &ast.IndexExpr{X: x, Index: r.index()}
+		case r.accept("of"):
+			x = r.callArgs(x)
+		case r.accept("as type"):
+			assert := &ast.TypeAssertExpr{X: x}
+			if r.atTypeStart() {
+				assert.Type = r.parseType(false)
+			}
+			x = assert
+		case r.at("containing") && isType(x):
+			r.accept("containing")
+			x = &ast.CompositeLit{Type: x, Elts: r.elements()}
+		case r.at("containing") && isInstantiation(x):
+			r.accept("containing")
+			x = &ast.CompositeLit{Type: instantiate(x.(*ast.CallExpr)), Elts: r.elements()}
+		case r.accept("element array of"):
+			x = &ast.ArrayType{Len: x, Elt: r.parseType(false)}
+		default:
+			return x
+		}
+	}
+}
+
+// selector reads the name after "dot". Only exported names can be used
+// from another package, so a name that had to be put back together from a
+// pronunciation, such as fprintf, is capitalized there.
+func (r *reader) selector(x ast.Expr) ast.Expr {
+	sel := r.ident()
+	if pkg, ok := x.(*ast.Ident); ok && r.packages[pkg.Name] {
+		ch, size := utf8.DecodeRuneInString(sel.Name)
+		sel.Name = string(unicode.ToUpper(ch)) + sel.Name[size:]
+	}
+	return &ast.SelectorExpr{X: x, Sel: sel}
+}
+
+func isType(x ast.Expr) bool {
+	switch v := x.(type) {
+	case *ast.Ident, *ast.ArrayType, *ast.MapType, *ast.StructType, *ast.IndexExpr,
+		*ast.IndexListExpr:
+		return true
+	case *ast.SelectorExpr:
+		return isType(v.X)
+	}
+	return false
+}
+
+// index reads what follows "sub", as tightly as it can be read, so "a sub
+// i plus one" is a[i] + 1 and "a sub i dot x" is a[i].x.
+func (r *reader) index() ast.Expr {
+	x := r.parseOperand()
+	if pkg, ok := x.(*ast.Ident); ok && r.packages[pkg.Name] && r.accept("dot") {
+		x = r.selector(x)
+	}
+	if r.accept("of") {
+		x = r.callArgs(x)
+	}
+	return x
+}
+
+// spokenBuiltins are the words for builtin functions that are said in
+// full, as in "length of s".
+var spokenBuiltins = map[string]string{
+	"length":   "len",
+	"capacity": "cap",
+}
+
+// callArgs reads the arguments after "of". A lone argument is read as
+// tightly as it can be, so "len of s minus one" is len(s) - 1; after a
+// comma, arguments go on for as long as they can.
+func (r *reader) callArgs(fun ast.Expr) *ast.CallExpr {
+	if ident, ok := fun.(*ast.Ident); ok && !r.declared(ident.Name) {
+		if builtin, ok := spokenBuiltins[ident.Name]; ok {
+			ident.Name = builtin
+		}
+	}
+	call := &ast.CallExpr{Fun: fun}
+	start := r.pos
+	call.Args = append(call.Args, r.parseExpr())
+	if !r.at("comma") {
+		r.pos = start
+		call.Args[0] = r.parseUnary()
+	}
+	for r.at("comma") && !r.atAfterKeyed("comma") {
+		r.accept("comma")
+		call.Args = append(call.Args, r.parseExpr())
+	}
+	if r.accept("ellipsis") {
+		call.Ellipsis = 1
+	}
+	return call
+}
+
+// noArgCall makes a call without arguments of what follows "call". When
+// that is itself a call, as in "call exec dot Command of name comma arg
+// dot Run", the selector after its last argument is taken to be the method
+// being called: exec.Command(name, arg).Run().
+func noArgCall(fun ast.Expr) ast.Expr {
+	if call, ok := fun.(*ast.CallExpr); ok && len(call.Args) > 0 && !call.Ellipsis.IsValid() {
+		last := len(call.Args) - 1
+		if sel, ok := call.Args[last].(*ast.SelectorExpr); ok {
+			call.Args[last] = sel.X
+			return &ast.CallExpr{Fun: &ast.SelectorExpr{X: call, Sel: sel.Sel}}
+		}
+	}
+	return &ast.CallExpr{Fun: fun}
+}
+
+// isInstantiation reports whether a call is really a generic type with its
+// type arguments, as in "Pair of int and string containing ...".
+func isInstantiation(x ast.Expr) bool {
+	call, ok := x.(*ast.CallExpr)
+	return ok && len(call.Args) == 1 && isType(call.Fun)
+}
+
+func instantiate(call *ast.CallExpr) ast.Expr {
+	var args []ast.Expr
+	var flatten func(x ast.Expr)
+	flatten = func(x ast.Expr) {
+		if b, ok := x.(*ast.BinaryExpr); ok && b.Op == token.LAND {
+			flatten(b.X)
+			flatten(b.Y)
+			return
+		}
+		args = append(args, x)
+	}
+	flatten(call.Args[0])
+	if len(args) == 1 {
+		return &ast.IndexExpr{X: call.Fun, Index: args[0]}
+	}
+	return &ast.IndexListExpr{X: call.Fun, Indices: args}
+}
+
+// elements reads the elements of a composite literal, separated by
+// "comma". A list without keys inside one with them ends at the next key.
+func (r *reader) elements() []ast.Expr {
+	elts := []ast.Expr{}
+	keyed := false
+	for {
+		if r.accept("key") {
+			kv := &ast.KeyValueExpr{Key: r.element()}
+			r.expect("with value")
+			kv.Value = r.element()
+			elts = append(elts, kv)
+			keyed = true
+		} else {
+			elts = append(elts, r.element())
+		}
+		if !r.at("comma") || (!keyed && r.atAfterKeyed("comma")) {
+			return elts
+		}
+		r.accept("comma")
+	}
+}
+
+// element reads an element of a composite literal, which may be a literal
+// whose type was left out.
+func (r *reader) element() ast.Expr {
+	if r.accept("containing") {
+		return &ast.CompositeLit{Elts: r.elements()}
+	}
+	return r.parseExpr()
+}
+
+func (r *reader) parseOperand() ast.Expr {
+	if r.atEnd() {
+		r.errorf("expected an expression")
+	}
+	if r.words[r.pos].literal {
+		return r.stringLit()
+	}
+	if lit := r.literal(); lit != nil {
+		return lit
+	}
+
+	switch {
+	case r.accept("left paren"):
+		paren := &ast.ParenExpr{X: r.parseExpr()}
+		r.expect("right paren")
+		return paren
+	case r.accept("call"):
+		return noArgCall(r.parsePrimary())
+	case r.accept("lambda"):
+		r.pushScope()
+		defer r.popScope()
+		lit := &ast.FuncLit{Type: &ast.FuncType{}}
+		r.signature(lit.Type)
+		r.declareFields(lit.Type.Params, lit.Type.Results)
+		r.expect("is")
+		lit.Body = r.block("end lambda")
+		return lit
+	case r.accept("slice"):
+		slice := &ast.SliceExpr{X: r.parseExpr()}
+		r.expect("from")
+		if !r.accept("start") {
+			slice.Low = r.parseExpr()
+		}
+		if !r.accept("to end") {
+			r.expect("to")
+			slice.High = r.parseExpr()
+		}
+		if r.accept("with cap") {
+			slice.Max = r.parseExpr()
+			slice.Slice3 = true
+		}
+		return slice
+	case r.at("empty") && !r.atEmptyType():
+		r.accept("empty")
+		lit := &ast.CompositeLit{}
+		if r.atTypeStart() {
+			lit.Type = r.parseType(true)
+		}
+		return lit
+	}
+	// Where an expression must come, a name that is also a keyword, such
+	// as from, is read as a name
+	if r.atTypeStart() || r.atStopPrefix() || (r.phrased && !r.atStop()) {
+		return r.parseType(false)
+	}
+	r.errorf("expected an expression")
+	return nil
+}
+
+// atEmptyType tells "empty struct", the type, from "empty" before a struct
+// type with fields, which is an empty composite literal.
+func (r *reader) atEmptyType() bool {
+	if r.at("empty interface") {
+		return true
+	}
+	return r.at("empty struct") && !r.atAfter("empty struct", "having")
+}
+
+// parseType reads a type. In declarations, "of" gives the type arguments
+// of a generic type; in expressions it calls a conversion.
+func (r *reader) parseType(isDecl bool) ast.Expr {
+	switch {
+	case r.accept("pointer to") || r.accept("contents of") || r.accept("star"):
+		return &ast.StarExpr{X: r.parseType(isDecl)}
+	case r.accept("left paren"):
+		paren := &ast.ParenExpr{X: r.parseType(isDecl)}
+		r.expect("right paren")
+		return paren
+	case r.accept("underlying type"):
+		return &ast.UnaryExpr{Op: token.TILDE, X: r.parseType(isDecl)}
+	case r.accept("slice of"):
+		return &ast.ArrayType{Elt: r.parseType(isDecl)}
+	case r.accept("map"):
+		m := &ast.MapType{}
+		r.expect("with")
+		m.Key = r.parseType(isDecl)
+		r.expect("key")
+		r.expect("and")
+		m.Value = r.parseType(isDecl)
+		r.expect("value")
+		return m
+	case r.accept("function"):
+		ft := &ast.FuncType{}
+		r.signature(ft)
+		return ft
+	case r.accept("empty interface"):
+		return &ast.InterfaceType{Methods: &ast.FieldList{Opening: 1, Closing: 1}}
+	case r.accept("interface"):
+		return r.interfaceType()
+	case r.at("empty struct") && !r.atAfter("empty struct", "having"):
+		r.accept("empty struct")
+		return &ast.StructType{Fields: &ast.FieldList{Opening: 1, Closing: 1}}
+	case r.accept("struct"):
+		st := &ast.StructType{Fields: r.fieldList("having", "field", "fields")}
+		if st.Fields == nil {
+			st.Fields = &ast.FieldList{}
+		}
+		return st
+	case r.accept("send to channel"):
+		return &ast.ChanType{Dir: ast.SEND, Value: r.parseType(isDecl)}
+	case r.accept("received from channel"):
+		return &ast.ChanType{Dir: ast.SEND | ast.RECV, Value: r.parseType(isDecl)}
+	case r.accept("variable number of"):
+		return &ast.Ellipsis{Elt: r.parseType(isDecl)}
+	case r.accept("variable number"):
+		return &ast.Ellipsis{}
+	}
+
+	var x ast.Expr
+	if lit := r.literal(); lit != nil {
+		x = lit
+	} else {
+		name := r.ident()
+		x = name
+		if r.packages[name.Name] && r.accept("dot") {
+			x = r.selector(x)
+		}
+	}
+	if r.accept("element array of") {
+		return &ast.ArrayType{Len: x, Elt: r.parseType(isDecl)}
+	}
+	if isDecl && r.accept("of") {
+		args := []ast.Expr{r.parseType(true)}
+		for r.accept("and") {
+			args = append(args, r.parseType(true))
+		}
+		if len(args) == 1 {
+			return &ast.IndexExpr{X: x, Index: args[0]}
+		}
+		return &ast.IndexListExpr{X: x, Indices: args}
+	}
+	return x
+}
+
+func (r *reader) interfaceType() *ast.InterfaceType {
+	iface := &ast.InterfaceType{Methods: &ast.FieldList{}}
+	for {
+		if r.accept("embedding") {
+			iface.Methods.List = append(iface.Methods.List, &ast.Field{Type: r.parseType(true)})
+		} else if r.accept("with type set") {
+			iface.Methods.List = append(iface.Methods.List, &ast.Field{Type: r.constraint()})
+		} else {
+			break
+		}
+	}
+	if methods := r.fieldList("having", "method", "methods"); methods != nil {
+		iface.Methods.List = append(iface.Methods.List, methods.List...)
+	}
+	return iface
+}
+
+// stringLit makes a string literal of literal words. Interpreted strings
+// are spoken in pieces broken at their escapes, which are named, so a
+// piece holding a quote or backslash came from a raw string.
+func (r *reader) stringLit() *ast.BasicLit {
+	if r.atEnd() || !r.words[r.pos].literal {
+		r.errorf("expected a string")
+	}
+	first := r.words[r.pos]
+	var sb strings.Builder
+	for {
+		w := r.words[r.pos]
+		if w.escape {
+			sb.WriteString(r.escapeSequence(w.text))
+		} else {
+			quoted := strconv.Quote(w.text)
+			sb.WriteString(quoted[1 : len(quoted)-1])
+		}
+		r.pos++
+		if r.atEnd() || !r.words[r.pos].literal || !(w.escape || r.words[r.pos].escape) {
+			break
+		}
+	}
+	if !first.escape && r.words[r.pos-1] == first && strings.ContainsAny(first.text, "\"\\") &&
+		!strings.Contains(first.text, "`") {
+		return &ast.BasicLit{Kind: token.STRING, Value: "`" + first.text + "`"}
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: `"` + sb.String() + `"`}
+}
+
+// escapeSequence turns the name of an escape sequence in a string, such as
+// "newline" or "hex 4 1", back into the sequence.
+func (r *reader) escapeSequence(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		r.errorf("expected an escape sequence")
+	}
+	digits := strings.Join(fields[1:], "")
+	switch strings.ToLower(fields[0]) {
+	case "hex":
+		return `\x` + digits
+	case "unicode":
+		if len(digits) > 4 {
+			return `\U` + digits
+		}
+		return `\u` + digits
+	case "octal":
+		return `\` + digits
+	}
+	if strings.EqualFold(name, "double quote") {
+		// Only a character leaves the quote bare
+		return `\"`
+	}
+	if value, ok := escapeNames[strings.ToLower(name)]; ok {
+		return value
+	}
+	r.errorf("unknown escape sequence %s", name)
+	return ""
+}
+
+var escapeNames = map[string]string{
+	"bell": `\a`, "backspace": `\b`, "form feed": `\f`, "newline": `\n`,
+	"carriage return": `\r`, "tab": `\t`, "vertical tab": `\v`, "backslash": `\\`,
+	"single quote": `\'`, "double quote": `"`,
+}
+
+// literal reads a number, character or string said in words, or returns
+// nil.
+func (r *reader) literal() ast.Expr {
+	// A package such as binary is only a word of its own
+	for _, prefix := range []string{"character", "hex", "octal", "binary", "imaginary", "string of"} {
+		if n := r.match(prefix, true); n > 0 && !r.continuesAfter(n) {
+			return nil
+		}
+	}
+	switch {
+	case r.accept("empty string"):
+		return &ast.BasicLit{Kind: token.STRING, Value: `""`}
+	case r.accept("empty raw string"):
+		return &ast.BasicLit{Kind: token.STRING, Value: "``"}
+	case r.accept("string with one blank"):
+		return &ast.BasicLit{Kind: token.STRING, Value: `" "`}
+	case r.acceptPrefix("string of"):
+		n, ok := r.number()
+		if !ok {
+			r.errorf("expected a number of blanks")
+		}
+		r.expectPrefix("blanks")
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(strings.Repeat(" ", int(n)))}
+	case r.accept("space character"):
+		return &ast.BasicLit{Kind: token.CHAR, Value: `' '`}
+	case r.acceptPrefix("character"):
+		ch := r.words[r.pos].text
+		r.pos++
+		return &ast.BasicLit{Kind: token.CHAR, Value: "'" + ch + "'"}
+	case r.acceptPrefix("hex"):
+		return r.digits("0x", "0123456789abcdef", "times two to the", "p")
+	case r.acceptPrefix("octal"):
+		return r.digits("0", "01234567", "", "")
+	case r.acceptPrefix("binary"):
+		return r.digits("0b", "01", "", "")
+	case r.acceptPrefix("imaginary"):
+		number := r.literal()
+		lit, ok := number.(*ast.BasicLit)
+		if !ok {
+			r.errorf("expected a number")
+		}
+		return &ast.BasicLit{Kind: token.IMAG, Value: lit.Value + "i"}
+	}
+	if ch := r.namedCharacter(); ch != nil {
+		return ch
+	}
+
+	n, ok := r.number()
+	if !ok {
+		return nil
+	}
+	lit := &ast.BasicLit{Kind: token.INT, Value: strconv.FormatUint(n, 10)}
+	if r.acceptPrefix("point") {
+		lit.Kind = token.FLOAT
+		lit.Value += "."
+		for !r.atEnd() && r.words[r.pos-1].phrase == r.words[r.pos].phrase {
+			digit, ok := smallNumbers[r.words[r.pos].lower]
+			if !ok || digit > 9 {
+				break
+			}
+			lit.Value += strconv.Itoa(int(digit))
+			r.pos++
+		}
+	}
+	if exponent := r.exponent("times ten to the"); exponent != "" {
+		lit.Kind = token.FLOAT
+		lit.Value += "e" + exponent
+	}
+	return lit
+}
+
+// exponent reads an exponent introduced by a phrase such as "times ten to
+// the".
+func (r *reader) exponent(intro string) string {
+	if intro == "" || !r.acceptPrefix(intro) {
+		return ""
+	}
+	sign := ""
+	if r.acceptPrefix("minus") {
+		sign = "-"
+	}
+	n, ok := r.number()
+	if !ok {
+		r.errorf("expected an exponent")
+	}
+	return sign + strconv.FormatUint(n, 10)
+}
+
+// digits reads the digits of a hex, octal or binary number, which are
+// spoken one at a time.
+func (r *reader) digits(prefix, valid, exponentIntro, exponentLetter string) ast.Expr {
+	lit := &ast.BasicLit{Kind: token.INT, Value: prefix}
+	for !r.atEnd() && r.words[r.pos-1].phrase == r.words[r.pos].phrase {
+		w := r.words[r.pos].text
+		if r.words[r.pos].lower == "point" && prefix == "0x" {
+			lit.Kind = token.FLOAT
+			lit.Value += "."
+			r.pos++
+			continue
+		}
+		if strings.Trim(strings.ToLower(w), valid) != "" {
+			break
+		}
+		lit.Value += w
+		r.pos++
+	}
+	if lit.Value == prefix {
+		r.errorf("expected digits")
+	}
+	if exponent := r.exponent(exponentIntro); exponent != "" {
+		lit.Kind = token.FLOAT
+		lit.Value += exponentLetter + exponent
+	}
+	return lit
+}
+
+// namedCharacter reads a character spoken by name, as in "newline
+// character" or "comma character".
+func (r *reader) namedCharacter() ast.Expr {
+	for n := 1; n <= 3 && r.pos+n < len(r.words); n++ {
+		if r.words[r.pos+n].lower != "character" || r.words[r.pos+n].phrase != r.words[r.pos].phrase {
+			continue
+		}
+		words := []string{}
+		for _, w := range r.words[r.pos : r.pos+n] {
+			words = append(words, w.lower)
+		}
+		name := strings.Join(words, " ")
+		value, ok := escapeNames[name]
+		if !ok {
+			value, ok = r.symbolNames()[name]
+		}
+		if !ok {
+			return nil
+		}
+		r.pos += n + 1
+		return &ast.BasicLit{Kind: token.CHAR, Value: "'" + value + "'"}
+	}
+	return nil
+}
+
+// symbolNames maps the spoken names of punctuation back to the characters.
+func (r *reader) symbolNames() map[string]string {
+	names := map[string]string{}
+	for symbol, speech := range gospeak.BuiltinPronunciations() {
+		if utf8.RuneCountInString(symbol) == 1 && !isIdentifier(symbol) {
+			names[strings.ToLower(speech)] = symbol
+		}
+	}
+	return names
+}
@@ -0,0 +1,315 @@
+package gospeak
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind says whether lines were added, removed or modified.
+type ChangeKind int
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	}
+	return "modified"
+}
+
+// Change is one hunk of a diff. Start and End are the changed lines in the
+// new version of the file, and OldStart and OldEnd the lines they replace in
+// the old version. For an addition OldEnd is before OldStart, and for a
+// removal End is before Start, with Start the line following the removed
+// code.
+type Change struct {
+	Kind     ChangeKind
+	Start    int
+	End      int
+	OldStart int
+	OldEnd   int
+}
+
+// FileDiff holds the changes made to one file. OldFilename is empty for a
+// new file and Filename is empty for a deleted one.
+type FileDiff struct {
+	OldFilename string
+	Filename    string
+	Changes     []Change
+}
+
+// ParseDiff reads the output of git diff, or any unified diff, and returns
+// the changes to each file. Context lines are ignored, so the diff is best
+// produced with -U0.
+func ParseDiff(r io.Reader) ([]FileDiff, error) {
+	diffs := []FileDiff{}
+	var curr *FileDiff
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			diffs = append(diffs, FileDiff{OldFilename: diffFilename(line[4:], "a/")})
+			curr = &diffs[len(diffs)-1]
+		case strings.HasPrefix(line, "+++ ") && curr != nil:
+			curr.Filename = diffFilename(line[4:], "b/")
+		case strings.HasPrefix(line, "@@ ") && curr != nil:
+			change, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			curr.Changes = append(curr.Changes, change)
+		}
+	}
+	return diffs, scanner.Err()
+}
+
+func diffFilename(name, prefix string) string {
+	if i := strings.Index(name, "\t"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// parseHunkHeader reads a header of the form @@ -oldStart,oldCount
+// +newStart,newCount @@, where a missing count means one line.
+func parseHunkHeader(header string) (Change, error) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return Change{}, fmt.Errorf("malformed hunk header %q", header)
+	}
+	oldStart, oldCount, err := parseHunkRange(fields[1][1:])
+	if err != nil {
+		return Change{}, fmt.Errorf("malformed hunk header %q: %+v", header, err)
+	}
+	newStart, newCount, err := parseHunkRange(fields[2][1:])
+	if err != nil {
+		return Change{}, fmt.Errorf("malformed hunk header %q: %+v", header, err)
+	}
+
+	change := Change{
+		Kind:     ChangeModified,
+		Start:    newStart,
+		End:      newStart + newCount - 1,
+		OldStart: oldStart,
+		OldEnd:   oldStart + oldCount - 1,
+	}
+	// An empty side of a hunk names the line before the change
+	if oldCount == 0 {
+		change.Kind = ChangeAdded
+		change.OldStart++
+		change.OldEnd = change.OldStart - 1
+	} else if newCount == 0 {
+		change.Kind = ChangeRemoved
+		change.Start++
+		change.End = change.Start - 1
+	}
+	return change, nil
+}
+
+func parseHunkRange(r string) (int, int, error) {
+	count := 1
+	if i := strings.Index(r, ","); i >= 0 {
+		var err error
+		count, err = strconv.Atoi(r[i+1:])
+		if err != nil {
+			return 0, 0, err
+		}
+		r = r[:i]
+	}
+	start, err := strconv.Atoi(r)
+	return start, count, err
+}
+
+// FunctionAt returns the name of the function declared around a line of
+// the loaded file, or an empty string if the line is outside any function.
+func (gsp *goSpeaker) FunctionAt(line int) string {
+	if fd := gsp.funcDeclAt(line); fd != nil {
+		return fd.Name.String()
+	}
+	return ""
+}
+
+func (gsp *goSpeaker) funcDeclAt(line int) *ast.FuncDecl {
+	if gsp.file == nil {
+		return nil
+	}
+	for _, decl := range gsp.file.Decls {
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		if gsp.fileSet.Position(fd.Pos()).Line <= line && gsp.fileSet.Position(fd.End()).Line >= line {
+			return fd
+		}
+	}
+	return nil
+}
+
+func (gsp *goSpeaker) functionAtSpeech(line int) string {
+	fd := gsp.funcDeclAt(line)
+	if fd == nil {
+		return ""
+	}
+	return gsp.funcDeclSpeech(fd)
+}
+
+func (gsp *goSpeaker) funcDeclSpeech(fd *ast.FuncDecl) string {
+	return gsp.phrase("Diff function", "Method", fd.Recv != nil, "Name", gsp.symbolToSpeech(fd.Name.String()))
+}
+
+// SpeakDiff reads the changes made to one file: for each change, where it
+// is, what kind of change it is, the new code, and a summary of any code it
+// removed, taken from oldSource, the previous version of the file. The new
+// version is loaded from diff.Filename.
+func (gsp *goSpeaker) SpeakDiff(diff FileDiff, oldSource string) error {
+	var old *goSpeaker
+	if oldSource != "" {
+		old = &goSpeaker{
+			quiet:       true,
+			skipImports: gsp.skipImports,
+			startLine:   -1,
+			endLine:     -1,
+			lexicon:     gsp.lexicon,
+			locale:      gsp.locale,
+			profile:     gsp.profile,
+			logger:      gsp.logger,
+		}
+		if err := old.LoadStringErr(oldSource); err != nil {
+			gsp.logf("Unable to parse the old version of %s: %+v\n", diff.OldFilename, err)
+			old = nil
+		}
+	}
+
+	if diff.Filename == "" {
+		gsp.events = nil
+		gsp.speakPhrase("Diff deleted", "Name", gsp.symbolToSpeech(diff.OldFilename))
+		if old != nil {
+			old.summarizeRange(1, strings.Count(oldSource, "\n")+1)
+			gsp.speakPhrase("Diff held")
+			gsp.events = append(gsp.events, old.events...)
+		}
+		return gsp.speakBuffer()
+	}
+
+	if err := gsp.LoadFileErr(diff.Filename); err != nil {
+		return err
+	}
+	defer gsp.SetRange(gsp.startLine, gsp.endLine)
+	events := []SpeechEvent{}
+	for _, change := range diff.Changes {
+		events = append(events, gsp.changeEvents(change, old)...)
+	}
+
+	gsp.events = nil
+	if diff.OldFilename == "" {
+		gsp.speakPhrase("Diff new", "Name", gsp.symbolToSpeech(diff.Filename))
+	} else {
+		gsp.speakPhrase("Diff file", "Name", gsp.symbolToSpeech(diff.Filename))
+	}
+	gsp.events = append(gsp.events, events...)
+	return gsp.speakBuffer()
+}
+
+func (gsp *goSpeaker) changeEvents(change Change, old *goSpeaker) []SpeechEvent {
+	body := []SpeechEvent{}
+	if change.Kind != ChangeRemoved {
+		gsp.SetRange(change.Start, change.End)
+		gsp.render()
+		body = gsp.events
+	}
+
+	gsp.events = nil
+	switch change.Kind {
+	case ChangeAdded:
+		gsp.speakPhrase("Diff added", "Lines", gsp.lineRangeSpeech(change.Start, change.End),
+			"Function", gsp.functionAtSpeech(change.Start))
+	case ChangeRemoved:
+		// Code removed just before a function isn't inside it
+		function := ""
+		if fd := gsp.funcDeclAt(change.Start); fd != nil && gsp.fileSet.Position(fd.Pos()).Line < change.Start {
+			function = gsp.funcDeclSpeech(fd)
+		}
+		gsp.speakPhrase("Diff removed", "Count", change.OldEnd-change.OldStart+1,
+			"Line", change.Start, "Function", function)
+	default:
+		gsp.speakPhrase("Diff modified", "Lines", gsp.lineRangeSpeech(change.Start, change.End),
+			"Function", gsp.functionAtSpeech(change.Start))
+	}
+	gsp.events = append(gsp.events, body...)
+
+	if change.Kind != ChangeAdded && old != nil {
+		old.summarizeRange(change.OldStart, change.OldEnd)
+		if change.Kind == ChangeModified {
+			gsp.speakPhrase("Diff replacing")
+		} else {
+			gsp.speakPhrase("Diff held")
+		}
+		gsp.events = append(gsp.events, old.events...)
+	}
+	return gsp.events
+}
+
+func (gsp *goSpeaker) lineRangeSpeech(start, end int) string {
+	return gsp.phrase("Diff lines", "Start", start, "End", end)
+}
+
+// summarizeRange briefly describes the code between two lines: whole
+// declarations are read in outline form, and statements that were part of
+// a larger function are only counted.
+func (gsp *goSpeaker) summarizeRange(start, end int) {
+	gsp.events = nil
+	within := func(n ast.Node) bool {
+		return gsp.fileSet.Position(n.Pos()).Line >= start && gsp.fileSet.Position(n.End()).Line <= end
+	}
+	overlaps := func(n ast.Node) bool {
+		return gsp.fileSet.Position(n.Pos()).Line <= end && gsp.fileSet.Position(n.End()).Line >= start
+	}
+
+	for _, decl := range gsp.file.Decls {
+		if !overlaps(decl) {
+			continue
+		}
+		if within(decl) {
+			gsp.speakOutlineDeclaration(decl)
+			continue
+		}
+		fd, ok := decl.(*ast.FuncDecl)
+		if !ok || fd.Body == nil {
+			continue
+		}
+		statements := 0
+		ast.Inspect(fd.Body, func(n ast.Node) bool {
+			if _, ok := n.(*ast.BlockStmt); ok {
+				return true
+			}
+			if stmt, ok := n.(ast.Stmt); ok && within(stmt) {
+				statements++
+				return false
+			}
+			return n != nil && overlaps(n)
+		})
+		if statements > 0 {
+			gsp.speakPhrase("Diff statements", "Count", statements, "Function", gsp.funcDeclSpeech(fd))
+		}
+	}
+	if len(gsp.events) == 0 {
+		gsp.speakPhrase("Diff line count", "Count", end-start+1)
+	}
+}
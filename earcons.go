@@ -0,0 +1,278 @@
+package gospeak
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// Earcon is a short tone played in place of the words that mark structure,
+// such as "function body" and "end if".
+type Earcon struct {
+	// Frequency is the pitch in hertz at nesting depth zero. Each level of
+	// nesting raises it by a whole tone.
+	Frequency float64 `json:"frequency"`
+	// Duration is the length of the tone in milliseconds.
+	Duration int `json:"duration"`
+}
+
+// Earcons maps places in the code to their tones. Keys are a node kind, as
+// in SpeechEvent.Kind, followed by "start" or "end", such as "FuncDecl start"
+// or "IfStmt end". "IfStmt else" marks an else branch and "statement" is
+// played between the statements of a block. Places without an earcon are
+// still announced with words.
+type Earcons map[string]Earcon
+
+func DefaultEarcons() Earcons {
+	return Earcons{
+		"FuncDecl start":     {523.25, 90},
+		"FuncDecl end":       {392.00, 90},
+		"FuncLit start":      {587.33, 70},
+		"FuncLit end":        {440.00, 70},
+		"IfStmt start":       {659.25, 60},
+		"IfStmt else":        {554.37, 60},
+		"IfStmt end":         {493.88, 60},
+		"ForStmt start":      {783.99, 60},
+		"ForStmt end":        {587.33, 60},
+		"RangeStmt start":    {783.99, 60},
+		"RangeStmt end":      {587.33, 60},
+		"SwitchStmt end":     {659.25, 60},
+		"TypeSwitchStmt end": {659.25, 60},
+		"SelectStmt end":     {659.25, 60},
+		"BlockStmt start":    {440.00, 60},
+		"BlockStmt end":      {329.63, 60},
+		"statement":          {1046.50, 20},
+	}
+}
+
+// LoadEarcons reads earcons from a JSON file such as
+// {"FuncDecl start": {"frequency": 440, "duration": 100}}. The entries
+// replace the defaults for their keys; an entry with no duration removes
+// the earcon so that words are used again.
+func LoadEarcons(filename string) (Earcons, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]Earcon{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse earcons %s: %+v", filename, err)
+	}
+	earcons := DefaultEarcons()
+	for key, earcon := range entries {
+		if earcon.Duration <= 0 {
+			delete(earcons, key)
+			continue
+		}
+		earcons[key] = earcon
+	}
+	return earcons, nil
+}
+
+// SetEarcons plays tones instead of the words that mark blocks and
+// separate statements. Nil turns earcons off.
+func (gsp *goSpeaker) SetEarcons(earcons Earcons) {
+	gsp.earcons = earcons
+}
+
+// earconGap is the silence left after each earcon.
+const earconGap = 40 * time.Millisecond
+
+// earconMarker is the form an earcon takes in a speech script, giving its
+// frequency and duration.
+var earconMarker = regexp.MustCompile(`\{earcon ([0-9.]+) ([0-9]+)\}`)
+
+// earconPause matches an earcon and the pause after it, for backends that
+// can only leave a silence of the earcon's length.
+var earconPause = regexp.MustCompile(`\{earcon [0-9.]+ ([0-9]+)\}\{pause\}`)
+
+func earconScript(earcon Earcon) string {
+	return fmt.Sprintf("{earcon %.2f %d}", earcon.Frequency, earcon.Duration)
+}
+
+// parseEarcon returns the earcon a script phrase stands for, if it is one.
+func parseEarcon(phrase string) (Earcon, bool) {
+	m := earconMarker.FindStringSubmatch(phrase)
+	if m == nil {
+		return Earcon{}, false
+	}
+	frequency, _ := strconv.ParseFloat(m[1], 64)
+	duration, _ := strconv.Atoi(m[2])
+	return Earcon{frequency, duration}, true
+}
+
+// speakMarker plays the earcon for a place in the code, or speaks words
+// when there is none. No words means there is nothing to mark.
+func (gsp *goSpeaker) speakMarker(key string, words string) {
+	if words == "" {
+		return
+	}
+	earcon, ok := gsp.earcons[key]
+	if !ok {
+		gsp.speak(words)
+		return
+	}
+	earcon.Frequency *= math.Pow(2, float64(gsp.depth)/6)
+	event := gsp.makeEvent(earconScript(earcon))
+	event.earcon = true
+	gsp.addEvent(event)
+}
+
+// speakSeparator plays the statement earcon, if there is one.
+func (gsp *goSpeaker) speakSeparator() {
+	if _, ok := gsp.earcons["statement"]; ok {
+		gsp.speakMarker("statement", "statement")
+	}
+}
+
+// WAVPlayer is implemented by backends that can play a WAV file, which is
+// how speech mixed with earcons is heard.
+type WAVPlayer interface {
+	PlayWAV(wavFile string) error
+}
+
+func writeWAV(filename string, format wavFormat, samples []byte) error {
+	header := make([]byte, 44)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(36+len(samples)))
+	copy(header[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(header[16:], 16)
+	binary.LittleEndian.PutUint16(header[20:], 1)
+	binary.LittleEndian.PutUint16(header[22:], uint16(format.channels))
+	binary.LittleEndian.PutUint32(header[24:], uint32(format.sampleRate))
+	binary.LittleEndian.PutUint32(header[28:], uint32(format.byteRate()))
+	binary.LittleEndian.PutUint16(header[32:], uint16(format.channels*format.bitsPerSample/8))
+	binary.LittleEndian.PutUint16(header[34:], uint16(format.bitsPerSample))
+	copy(header[36:], "data")
+	binary.LittleEndian.PutUint32(header[40:], uint32(len(samples)))
+	return ioutil.WriteFile(filename, append(header, samples...), 0644)
+}
+
+// toneSamples generates a sine tone as 16-bit samples, fading in and out
+// over a few milliseconds so that it doesn't click.
+func toneSamples(format wavFormat, frequency float64, ms int) []byte {
+	count := format.sampleRate * ms / 1000
+	fade := format.sampleRate * 5 / 1000
+	samples := make([]byte, 0, count*format.channels*2)
+	for i := 0; i < count; i++ {
+		gain := 1.0
+		if i < fade {
+			gain = float64(i) / float64(fade)
+		} else if count-i < fade {
+			gain = float64(count-i) / float64(fade)
+		}
+		v := int16(0.3 * gain * math.MaxInt16 * math.Sin(2*math.Pi*frequency*float64(i)/float64(format.sampleRate)))
+		for c := 0; c < format.channels; c++ {
+			samples = append(samples, byte(v), byte(uint16(v)>>8))
+		}
+	}
+	return samples
+}
+
+func silence(format wavFormat, ms int) []byte {
+	return make([]byte, format.sampleRate*ms/1000*format.channels*format.bitsPerSample/8)
+}
+
+// mixEarcons synthesizes each phrase and joins them with generated tones,
+// if there are any, into a single WAV file. The length of each phrase is
+// kept to time the captions with.
+func (gsp *goSpeaker) mixEarcons(ctx context.Context, synth PhraseSynthesizer, wavFile string) error {
+	dir, err := ioutil.TempDir("", "gospeak")
+	if err != nil {
+		return fmt.Errorf("unable to create temp dir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	format := wavFormat{channels: 1, sampleRate: 22050, bitsPerSample: 16}
+	phrases := map[int][]byte{}
+	gsp.phraseDurations = map[string]time.Duration{}
+	for i, e := range gsp.events {
+		if e.earcon {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		phraseFile := filepath.Join(dir, strconv.Itoa(i)+".wav")
+		err := synth.SynthesizePhrase(ctx, e.Phrase, phraseFile)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("unable to synthesize %s: %+v", e.Phrase, err)
+		}
+		f, samples, err := readWAV(phraseFile)
+		if err != nil {
+			return err
+		}
+		if len(phrases) == 0 {
+			format = f
+		} else if f != format {
+			return errors.New("phrases were synthesized in different formats")
+		}
+		phrases[i] = samples
+		gsp.phraseDurations[e.Phrase] = f.duration(samples)
+	}
+	if format.bitsPerSample != 16 {
+		return fmt.Errorf("earcons need 16-bit audio, not %d-bit", format.bitsPerSample)
+	}
+
+	mixed := []byte{}
+	for i, e := range gsp.events {
+		if earcon, ok := parseEarcon(e.Phrase); e.earcon && ok {
+			mixed = append(mixed, toneSamples(format, earcon.Frequency, earcon.Duration)...)
+			mixed = append(mixed, silence(format, int(earconGap.Milliseconds()))...)
+			continue
+		}
+		mixed = append(mixed, phrases[i]...)
+		mixed = append(mixed, silence(format, int(phrasePause.Milliseconds()))...)
+	}
+	return writeWAV(wavFile, format, mixed)
+}
+
+// hasEarconEvents reports whether there are any tones to mix into the
+// speech; without them the backend reads the script as usual.
+func (gsp *goSpeaker) hasEarconEvents() bool {
+	for _, e := range gsp.events {
+		if e.earcon {
+			return true
+		}
+	}
+	return false
+}
+
+// speakEarcons speaks the events with their earcons mixed in, saving the
+// result to the audio output file or playing it.
+func (gsp *goSpeaker) speakEarcons(ctx context.Context, synth PhraseSynthesizer) error {
+	if gsp.audioOutputFile != "" {
+		return gsp.mixEarcons(ctx, synth, gsp.audioOutputFile)
+	}
+	player, ok := gsp.backend.(WAVPlayer)
+	if !ok {
+		return errors.New("the speech backend can't play earcons")
+	}
+	tempFile, err := ioutil.TempFile("", "gospeech*.wav")
+	if err != nil {
+		return fmt.Errorf("unable to create temp file: %+v", err)
+	}
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	if err := gsp.mixEarcons(ctx, synth, tempFile.Name()); err != nil {
+		return err
+	}
+	if player, ok := player.(ContextWAVPlayer); ok {
+		return player.PlayWAVContext(ctx, tempFile.Name())
+	}
+	return player.PlayWAV(tempFile.Name())
+}
@@ -0,0 +1,123 @@
+package gospeak
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type espeakBackend struct {
+	voice VoiceSettings
+}
+
+func MakeESpeakBackend(voice VoiceSettings) SpeechBackend {
+	return &espeakBackend{
+		voice: voice,
+	}
+}
+
+func (eb *espeakBackend) Speak(script string, audioOutputFile string) error {
+	return eb.SpeakContext(context.Background(), script, audioOutputFile)
+}
+
+func (eb *espeakBackend) SpeakContext(ctx context.Context, script string, audioOutputFile string) error {
+	tempFile, err := ioutil.TempFile(".", "gospeech")
+	if err != nil {
+		return fmt.Errorf("unable to create temp file: %+v", err)
+	}
+	tempFile.WriteString(espeakMarkup(script))
+	tempFile.Close()
+	defer os.Remove(tempFile.Name())
+
+	cmd := exec.CommandContext(ctx, "espeak-ng", eb.args(tempFile.Name(), audioOutputFile)...)
+	err = runSpeechCommand(ctx, cmd)
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("unable to run espeak-ng: %+v", err)
+	}
+	return err
+}
+
+func (eb *espeakBackend) SynthesizePhrase(ctx context.Context, phrase string, wavFile string) error {
+	args := append(eb.voiceArgs(), "-w", wavFile, "--stdin")
+	cmd := exec.CommandContext(ctx, "espeak-ng", args...)
+	cmd.Stdin = strings.NewReader(stripMarkers(phrase))
+	return runSpeechCommand(ctx, cmd)
+}
+
+// PlayWAV plays a WAV file with whichever of the usual Linux players is
+// installed.
+func (eb *espeakBackend) PlayWAV(wavFile string) error {
+	return eb.PlayWAVContext(context.Background(), wavFile)
+}
+
+func (eb *espeakBackend) PlayWAVContext(ctx context.Context, wavFile string) error {
+	for _, player := range []string{"paplay", "aplay", "play"} {
+		if _, err := exec.LookPath(player); err != nil {
+			continue
+		}
+		err := runSpeechCommand(ctx, exec.CommandContext(ctx, player, wavFile))
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("unable to run %s: %+v", player, err)
+		}
+		return err
+	}
+	return fmt.Errorf("no audio player found to play %s", wavFile)
+}
+
+func (eb *espeakBackend) args(scriptFile string, audioOutputFile string) []string {
+	args := append([]string{"-m", "-f", scriptFile}, eb.voiceArgs()...)
+	if audioOutputFile != "" {
+		args = append(args, "-w", audioOutputFile)
+	}
+	return args
+}
+
+func (eb *espeakBackend) voiceArgs() []string {
+	args := []string{}
+	if eb.voice.Voice != "" {
+		args = append(args, "-v", eb.voice.Voice)
+	} else if !eb.voice.isEnglish() {
+		// espeak-ng names its voices by language
+		args = append(args, "-v", primaryLanguage(eb.voice.language()))
+	}
+	if eb.voice.Rate > 0 {
+		args = append(args, "-s", strconv.Itoa(eb.voice.Rate))
+	}
+	if eb.voice.Pitch >= 0 {
+		args = append(args, "-p", strconv.Itoa(eb.voice.Pitch))
+	}
+	return args
+}
+
+// espeakMarkup converts a speech script into the SSML subset that espeak-ng
+// reads when run with -m.
+func espeakMarkup(script string) string {
+	var sb strings.Builder
+	sb.WriteString("<speak>\n")
+	for _, phrase := range strings.Split(script, "{pause}") {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" {
+			continue
+		}
+		if earcon, ok := parseEarcon(phrase); ok {
+			sb.WriteString(fmt.Sprintf("<break time=\"%dms\"/>\n", earcon.Duration))
+			continue
+		}
+		sb.WriteString(escapeXML(stripMarkers(phrase)))
+		sb.WriteString(" <break time=\"200ms\"/>\n")
+	}
+	sb.WriteString("</speak>\n")
+	return sb.String()
+}
+
+func escapeXML(s string) string {
+	var buff bytes.Buffer
+	xml.EscapeText(&buff, []byte(s))
+	return buff.String()
+}
@@ -0,0 +1,119 @@
+package gospeak
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strings"
+)
+
+// SpeechEvent is a single spoken phrase along with the AST node that
+// produced it.
+type SpeechEvent struct {
+	Phrase   string
+	Start    token.Position
+	End      token.Position
+	Kind     string
+	Depth    int
+	Function string
+
+	literal bool
+	escape  bool
+	earcon  bool
+}
+
+// IsEarcon reports whether the event is a tone rather than words. Its
+// Phrase is then the markup the backends play the tone from.
+func (e SpeechEvent) IsEarcon() bool {
+	return e.earcon
+}
+
+func (gsp *goSpeaker) SpeechEvents() []SpeechEvent {
+	return gsp.events
+}
+
+func (gsp *goSpeaker) enterNode(n ast.Node) {
+	gsp.nodeStack = append(gsp.nodeStack, n)
+}
+
+func (gsp *goSpeaker) leaveNode() {
+	gsp.nodeStack = gsp.nodeStack[:len(gsp.nodeStack)-1]
+}
+
+func (gsp *goSpeaker) currentNode() ast.Node {
+	if len(gsp.nodeStack) == 0 {
+		return nil
+	}
+	return gsp.nodeStack[len(gsp.nodeStack)-1]
+}
+
+func (gsp *goSpeaker) makeEvent(speech string) SpeechEvent {
+	event := SpeechEvent{
+		Phrase: speech,
+		Depth:  gsp.depth,
+	}
+	if len(gsp.functionStack) > 0 {
+		event.Function = gsp.functionStack[len(gsp.functionStack)-1]
+	}
+	if n := gsp.currentNode(); n != nil {
+		event.Kind = nodeKind(n)
+		if gsp.fileSet != nil {
+			event.Start = gsp.fileSet.Position(n.Pos())
+			event.End = gsp.fileSet.Position(n.End())
+		}
+	}
+	return event
+}
+
+func nodeKind(n ast.Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+}
+
+// SpeechScript returns events as the script the speech backends read, with
+// each phrase followed by a pause.
+func SpeechScript(events []SpeechEvent) string {
+	var sb strings.Builder
+	for _, e := range events {
+		if e.literal {
+			sb.WriteString(literalMarker)
+		} else if e.escape {
+			sb.WriteString(escapeMarker)
+		}
+		sb.WriteString(e.Phrase)
+		sb.WriteString("{pause}\n")
+	}
+	return sb.String()
+}
+
+type jsonPhrase struct {
+	Speech   string `json:"speech"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Kind     string `json:"kind,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+// WriteSpeechJSON writes events as a JSON array with one object per phrase.
+// Earcons are left out, since they aren't speech.
+func WriteSpeechJSON(w io.Writer, events []SpeechEvent) error {
+	phrases := make([]jsonPhrase, 0, len(events))
+	for _, e := range events {
+		if e.earcon {
+			continue
+		}
+		phrases = append(phrases, jsonPhrase{
+			Speech:   e.Phrase,
+			File:     e.Start.Filename,
+			Line:     e.Start.Line,
+			Column:   e.Start.Column,
+			Kind:     e.Kind,
+			Function: e.Function,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(phrases)
+}
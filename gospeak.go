@@ -1,150 +1,293 @@
 package gospeak
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"go/ast"
 	"go/parser"
+	"go/scanner"
 	"go/token"
+	"go/types"
+	"io"
 	"io/ioutil"
 	"os"
-	"os/exec"
-	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 	"unicode"
-	"unicode/utf8"
 )
 
+var ErrNothingLoaded = errors.New("no Go source has been loaded")
+
+// GoSpeaker reads Go source aloud. The methods without an error result log
+// what went wrong; each has a variant that returns it instead, either with
+// Err at the end of its name or, for speaking what is loaded, with Context.
 type GoSpeaker interface {
 	SpeakGoFile(filename string)
 	SpeakGoFunction(filename string, function string)
 	SpeakGoString(s string)
+	SpeakGoFileErr(filename string) error
+	SpeakGoFunctionErr(filename string, function string) error
+	SpeakGoStringErr(s string) error
+	SpeakGoPackage(path string) error
 
 	LoadFile(filename string)
 	LoadString(s string)
+	LoadFileErr(filename string) error
+	LoadStringErr(s string) error
+	LoadPackage(path string) error
 
 	SpeakAll()
 	SpeakFunction(function string)
 	SpeakRange(start, end int)
+	SpeakAllContext(ctx context.Context) error
+	SpeakFunctionContext(ctx context.Context, function string) error
+	SpeakRangeContext(ctx context.Context, start, end int) error
+	SpeakErrors() error
+	SpeakDiff(diff FileDiff, oldSource string) error
+	SpeakChanges(previous string) error
+
+	Stop()
+	Pause() error
+	Resume() error
+
+	Navigator() (*Navigator, error)
+	FunctionAt(line int) string
 
 	SetRange(start, end int)
 	SetTargetFunction(function string)
+	SetCommentMode(mode CommentMode)
+	SetExportedOnly(exportedOnly bool)
+	SetTypeChecked(typeChecked bool)
+	SetLexicon(lexicon Lexicon)
+	SetEarcons(earcons Earcons)
+	SetCaptioned(captioned bool)
+	SetLocale(locale Locale)
+	SetProfile(profile Profile)
+	SetLogger(logger Logger)
+
+	SpeechEvents() []SpeechEvent
+	ParseErrors() scanner.ErrorList
+	Captions() []Caption
+	WriteCaptions(w io.Writer, format CaptionFormat) error
 }
 
 type goSpeaker struct {
 	quiet           bool
 	skipImports     bool
+	verbosity       Verbosity
 	targetFunction  string
 	startLine       int
 	endLine         int
 	audioOutputFile string
 	verboseOutput   bool
-
-	speechBuffer strings.Builder
-	fileSet      *token.FileSet
-	fileBuffer   string
+	backend         SpeechBackend
+	commentMode     CommentMode
+	exportedOnly    bool
+	typeChecked     bool
+	captioned       bool
+	lexicon         Lexicon
+	earcons         Earcons
+	locale          Locale
+	profile         Profile
+	logger          Logger
+
+	events      []SpeechEvent
+	fileSet     *token.FileSet
+	fileBuffer  string
+	parseErrors scanner.ErrorList
+	typesInfo   *types.Info
+	typesPkg    *types.Package
+	templates   map[string]*template.Template
+	// phraseDurations are the lengths of the phrases in the last audio
+	// put together a phrase at a time, which captions are timed with
+	phraseDurations map[string]time.Duration
 
 	functionStack []string
+	nodeStack     []ast.Node
+	depth         int
+	shallow       bool
 	file          *ast.File
+	pkg           *goPackage
+
+	nextComment    int
+	spokenComments map[*ast.CommentGroup]bool
+
+	playMutex sync.Mutex
+	playing   *playback
 }
 
 func MakeGoSpeakerDefault() GoSpeaker {
 	return &goSpeaker{
 		startLine: -1,
 		endLine:   -1,
+		backend:   MakeSayBackend(DefaultVoiceSettings()),
+		logger:    nopLogger{},
 	}
 }
 
-func MakeGoSpeaker(quiet bool, verbose bool, skipImports bool, audioOutputFile string) GoSpeaker {
+func MakeGoSpeaker(quiet bool, verbose bool, skipImports bool, verbosity Verbosity,
+	audioOutputFile string, backend SpeechBackend) GoSpeaker {
+	if backend == nil {
+		backend = MakeSayBackend(DefaultVoiceSettings())
+	}
 	return &goSpeaker{
 		quiet:           quiet,
 		verboseOutput:   verbose,
 		skipImports:     skipImports,
+		verbosity:       verbosity,
 		audioOutputFile: audioOutputFile,
 		startLine:       -1,
 		endLine:         -1,
+		backend:         backend,
+		logger:          nopLogger{},
 	}
 }
 
 func (gsp *goSpeaker) SpeakGoFile(filename string) {
-	gsp.LoadFile(filename)
-	if gsp.file != nil {
-		gsp.SpeakAll()
-	}
+	gsp.logError(gsp.SpeakGoFileErr(filename))
 }
 
 func (gsp *goSpeaker) SpeakGoFunction(filename string, function string) {
-	gsp.LoadFile(filename)
-	if gsp.file != nil {
-		gsp.SpeakFunction(function)
-	}
+	gsp.logError(gsp.SpeakGoFunctionErr(filename, function))
 }
 
 func (gsp *goSpeaker) SpeakGoString(s string) {
-	gsp.LoadString(s)
-	if gsp.file != nil {
-		gsp.SpeakAll()
-	}
+	gsp.logError(gsp.SpeakGoStringErr(s))
 }
 
-func (gsp *goSpeaker) LoadFile(filename string) {
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		gsp.speak("I can't find the file named " + speakableFilename(filename))
-		fmt.Printf("File %s does not exist\n", filename)
-		return
+func (gsp *goSpeaker) SpeakGoFileErr(filename string) error {
+	err := gsp.LoadFileErr(filename)
+	if err != nil {
+		return gsp.speakLoadError(filename, err)
 	}
+	return gsp.SpeakAllContext(context.Background())
+}
 
-	gsp.fileSet = token.NewFileSet() // positions are relative to fset
+func (gsp *goSpeaker) SpeakGoFunctionErr(filename string, function string) error {
+	err := gsp.LoadFileErr(filename)
+	if err != nil {
+		return gsp.speakLoadError(filename, err)
+	}
+	return gsp.SpeakFunctionContext(context.Background(), function)
+}
 
-	var err error
+func (gsp *goSpeaker) SpeakGoStringErr(s string) error {
+	err := gsp.LoadStringErr(s)
+	if err != nil {
+		return err
+	}
+	return gsp.SpeakAllContext(context.Background())
+}
 
-	gsp.file, err = parser.ParseFile(gsp.fileSet, filename, nil, parser.ParseComments)
-	if err != nil && gsp.file == nil {
-		panic(err)
+func (gsp *goSpeaker) speakLoadError(filename string, err error) error {
+	if os.IsNotExist(err) {
+		gsp.events = nil
+		gsp.speakPhrase("File missing", "Name", speakableFilename(filename))
+		if speakErr := gsp.speakBuffer(); speakErr != nil {
+			gsp.logf("Unable to speak: %+v\n", speakErr)
+		}
 	}
+	return err
+}
+
+func (gsp *goSpeaker) LoadFile(filename string) {
+	gsp.logError(gsp.LoadFileErr(filename))
+}
+
+func (gsp *goSpeaker) LoadString(s string) {
+	gsp.logError(gsp.LoadStringErr(s))
+}
+
+// LoadFileErr parses a Go source file. It only fails when nothing usable
+// could be parsed; if the parser recovered from errors, they are logged as
+// a warning and the partial file is kept.
+func (gsp *goSpeaker) LoadFileErr(filename string) error {
+	gsp.file = nil
+	gsp.pkg = nil
+	gsp.parseErrors = nil
+
+	source, err := ioutil.ReadFile(filename)
 	if err != nil {
-		fmt.Printf("Warning: file had compile errors: %+v\n", err)
+		return err
 	}
+	return gsp.parse(filename, source)
 }
 
-func (gsp *goSpeaker) LoadString(s string) {
-	gsp.fileBuffer = s
+func (gsp *goSpeaker) LoadStringErr(s string) error {
+	gsp.file = nil
+	gsp.pkg = nil
+	gsp.parseErrors = nil
+	return gsp.parse("buffer", []byte(s))
+}
 
+func (gsp *goSpeaker) parse(filename string, source []byte) error {
+	gsp.fileBuffer = string(source)
 	gsp.fileSet = token.NewFileSet() // positions are relative to fset
+	gsp.typesInfo = nil
 
-	var err error
-
-	gsp.file, err = parser.ParseFile(gsp.fileSet, "buffer", []byte(s), parser.ParseComments)
-	if err != nil && gsp.file == nil {
-		panic(err)
+	file, err := parser.ParseFile(gsp.fileSet, filename, source, parser.ParseComments)
+	if errList, ok := err.(scanner.ErrorList); ok {
+		// Kept even when nothing else is, so that SpeakErrors can read them
+		gsp.parseErrors = errList
+	}
+	if err != nil && (file == nil || !file.Package.IsValid()) {
+		// The parser gives up entirely without a package clause
+		return err
 	}
 	if err != nil {
-		fmt.Printf("Warning: file had compile errors: %+v\n", err)
+		gsp.logf("Warning: file had compile errors: %+v\n", err)
 	}
-
+	gsp.file = file
+	return nil
 }
 
 func (gsp *goSpeaker) SpeakAll() {
+	gsp.logError(gsp.SpeakAllContext(context.Background()))
+}
 
-	gsp.speakFile(gsp.file)
+func (gsp *goSpeaker) SpeakFunction(function string) {
+	gsp.logError(gsp.SpeakFunctionContext(context.Background(), function))
+}
 
-	gsp.speakBuffer()
+func (gsp *goSpeaker) SpeakRange(start, end int) {
+	gsp.logError(gsp.SpeakRangeContext(context.Background(), start, end))
 }
 
-func (gsp *goSpeaker) SpeakFunction(function string) {
+// SpeakAllContext is SpeakAll, with the speech stopped when ctx is done.
+// Speech that is cut short returns ctx.Err().
+func (gsp *goSpeaker) SpeakAllContext(ctx context.Context) error {
+	if !gsp.isLoaded() {
+		return ErrNothingLoaded
+	}
+
+	gsp.render()
+
+	return gsp.speakBufferContext(ctx)
+}
+
+func (gsp *goSpeaker) SpeakFunctionContext(ctx context.Context, function string) error {
+	if !gsp.isLoaded() {
+		return ErrNothingLoaded
+	}
 	gsp.targetFunction = function
 
-	gsp.speakFile(gsp.file)
+	gsp.render()
 
-	gsp.speakBuffer()
+	return gsp.speakBufferContext(ctx)
 }
 
-func (gsp *goSpeaker) SpeakRange(start, end int) {
+func (gsp *goSpeaker) SpeakRangeContext(ctx context.Context, start, end int) error {
+	if !gsp.isLoaded() {
+		return ErrNothingLoaded
+	}
 	gsp.startLine = start
 	gsp.endLine = end
 
-	gsp.speakFile(gsp.file)
+	gsp.render()
 
-	gsp.speakBuffer()
+	return gsp.speakBufferContext(ctx)
 }
 
 func (gsp *goSpeaker) SetRange(start, end int) {
@@ -157,7 +300,7 @@ func (gsp *goSpeaker) SetTargetFunction(function string) {
 }
 
 func (gsp *goSpeaker) GetSpeechString() string {
-	return gsp.speechBuffer.String()
+	return SpeechScript(gsp.events)
 }
 
 func (gsp *goSpeaker) isRanged() bool {
@@ -245,23 +388,64 @@ func (gsp *goSpeaker) isEndInRange(n ast.Node) bool {
 	return endPos.Line >= gsp.startLine && endPos.Line <= gsp.endLine
 }
 
+func (gsp *goSpeaker) isLoaded() bool {
+	return gsp.file != nil || gsp.pkg != nil
+}
+
+func (gsp *goSpeaker) render() {
+	gsp.events = nil
+	gsp.checkTypes()
+	if gsp.pkg != nil {
+		gsp.speakPackage(gsp.pkg)
+		return
+	}
+	gsp.speakFile(gsp.file)
+}
+
 func (gsp *goSpeaker) speakFile(file *ast.File) {
+	gsp.enterNode(file)
+	defer gsp.leaveNode()
+	gsp.resetComments()
+
+	gsp.speakParseErrorSummary()
+	gsp.speakDocComment(file.Doc)
+	gsp.speakCommentsBefore(file.Package)
 
-	if file.Name.String() != "" && gsp.isStartInRange(file) {
-		gsp.speak("package " + file.Name.String())
+	if file.Name.String() != "" && gsp.isStartInRange(file) && gsp.pkg == nil {
+		gsp.speakPhrase("File package", "Name", file.Name.String())
 	}
 
-	if !gsp.skipImports {
+	if !gsp.skipImports && !gsp.exportedOnly && gsp.verbosity != VerbosityOutline {
+		if len(file.Imports) > 0 {
+			gsp.speakCommentsBefore(file.Imports[0].Pos())
+		}
 		gsp.speakImportSpecs(file.Imports)
 	}
 
 	if !gsp.isRanged() && gsp.startLine < 0 && len(file.Decls) > 0 {
-		gsp.speak("declarations")
+		gsp.speakPhrase("File declarations")
 	}
 
 	for _, d := range file.Decls {
+		if gsp.exportedOnly {
+			if d = exportedDecl(d); d == nil {
+				continue
+			}
+		}
+		if gsp.verbosity == VerbosityOutline {
+			gsp.speakOutlineDeclaration(d)
+			continue
+		}
+		// The doc comment comes before the declaration's position, and is
+		// read as a doc comment by speakDeclaration
+		start := d.Pos()
+		if doc := declDoc(d); doc != nil {
+			start = doc.Pos()
+		}
+		gsp.speakCommentsBefore(start)
 		gsp.speakDeclaration(d)
 	}
+	gsp.speakCommentsBefore(gsp.fileEnd(file))
 }
 
 func speakableFilename(filename string) string {
@@ -277,7 +461,7 @@ func (gsp *goSpeaker) getFileString(from, to token.Pos) string {
 
 	bytesToRead := toPosition.Offset - fromPosition.Offset + 1
 	if bytesToRead < 0 {
-		fmt.Printf("From: %d  To: %d  Negative number of bytes\n",
+		gsp.logf("From: %d  To: %d  Negative number of bytes\n",
 			fromPosition.Offset, toPosition.Offset)
 		return ""
 	} else if bytesToRead == 0 {
@@ -285,26 +469,30 @@ func (gsp *goSpeaker) getFileString(from, to token.Pos) string {
 	}
 
 	if gsp.fileBuffer != "" {
-		return gsp.fileBuffer[fromPosition.Offset : toPosition.Offset+1]
+		end := toPosition.Offset + 1
+		if end > len(gsp.fileBuffer) {
+			end = len(gsp.fileBuffer)
+		}
+		return gsp.fileBuffer[fromPosition.Offset:end]
 	}
 
 	f, err := os.Open(fromPosition.Filename)
 	if err != nil {
-		fmt.Printf("Unable to open %s: %+v", fromPosition.Filename, err)
+		gsp.logf("Unable to open %s: %+v\n", fromPosition.Filename, err)
 		return ""
 	}
 	defer f.Close()
 
 	_, err = f.Seek(int64(fromPosition.Offset), 0)
 	if err != nil {
-		fmt.Printf("Error seeking in %s: %+v", fromPosition.Filename, err)
+		gsp.logf("Error seeking in %s: %+v\n", fromPosition.Filename, err)
 		return ""
 	}
 
 	buff := make([]byte, bytesToRead)
 	n, err := f.Read(buff)
 	if err != nil {
-		fmt.Printf("Error reading from %s: %+v", fromPosition.Filename, err)
+		gsp.logf("Error reading from %s: %+v\n", fromPosition.Filename, err)
 		return ""
 	}
 
@@ -323,112 +511,222 @@ var symbolTranslations = map[string]string{
 	"/":       "slash",
 	"\\":      "backslash",
 	"utf":     "you tee f",
+	"uint":    "you int",
 	"ast":     "eigh s t",
 	"a":       "eigh",
 	"strconv": "stir conv",
 	"_":       "none",
+	"json":    "jay son",
+	"ascii":   "ask ee",
+	"sql":     "sequel",
+	"regexp":  "reg exp",
 }
 
-func symbolToSpeech(sym string) string {
+// initialisms are spelled out letter by letter rather than read as words.
+var initialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"CPU":   true,
+	"CSS":   true,
+	"CSV":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IO":    true,
+	"IP":    true,
+	"LHS":   true,
+	"QPS":   true,
+	"RHS":   true,
+	"RPC":   true,
+	"SMTP":  true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"URI":   true,
+	"URL":   true,
+	"UUID":  true,
+	"VM":    true,
+	"XML":   true,
+	"XSRF":  true,
+	"XSS":   true,
+}
+
+func (gsp *goSpeaker) symbolToSpeech(sym string) string {
+	if speech, ok := gsp.lookupSymbol(sym); ok {
+		return speech
+	}
 	splits := splitSymbol(sym)
-	trans := translateSymbols(splits)
+	trans := gsp.translateSymbols(splits)
 	return strings.Join(trans, " ")
 }
 
+// splitSymbol breaks an identifier into words at underscores, at camel
+// case boundaries, after a run of capitals that starts the next word (so
+// HTTPServer becomes HTTP and Server), and around runs of digits. Other
+// characters, such as the dots and slashes in import paths, become words
+// of their own.
 func splitSymbol(symbol string) []string {
 	symbols := []string{}
-	currSymbol := []byte{}
-	runeBuff := make([]byte, 4)
-	for _, ch := range symbol {
-		if unicode.IsLetter(ch) {
-			n := utf8.EncodeRune(runeBuff, ch)
-			currSymbol = append(currSymbol, runeBuff[:n]...)
-		} else if len(currSymbol) > 0 {
+	currSymbol := []rune{}
+	flush := func() {
+		if len(currSymbol) > 0 {
 			symbols = append(symbols, string(currSymbol))
-			currSymbol = []byte{}
-			n := utf8.EncodeRune(runeBuff, ch)
-			symbols = append(symbols, string(runeBuff[:n]))
-		} else {
-			n := utf8.EncodeRune(runeBuff, ch)
-			symbols = append(symbols, string(runeBuff[:n]))
+			currSymbol = []rune{}
 		}
-
 	}
-	if len(currSymbol) > 0 {
-		symbols = append(symbols, string(currSymbol))
+
+	runes := []rune(symbol)
+	for i, ch := range runes {
+		if ch == '_' {
+			flush()
+			continue
+		}
+		if unicode.IsLetter(ch) {
+			if len(currSymbol) > 0 {
+				prev := currSymbol[len(currSymbol)-1]
+				if unicode.IsDigit(prev) {
+					flush()
+				} else if unicode.IsLower(prev) && unicode.IsUpper(ch) {
+					flush()
+				} else if unicode.IsUpper(prev) && unicode.IsUpper(ch) &&
+					i+1 < len(runes) && unicode.IsLower(runes[i+1]) && !isPluralInitialism(runes[i+1:]) {
+					flush()
+				}
+			}
+			currSymbol = append(currSymbol, ch)
+		} else if unicode.IsDigit(ch) {
+			if len(currSymbol) > 0 && !unicode.IsDigit(currSymbol[len(currSymbol)-1]) {
+				flush()
+			}
+			currSymbol = append(currSymbol, ch)
+		} else {
+			flush()
+			symbols = append(symbols, string(ch))
+		}
 	}
+	flush()
 
+	if len(symbols) == 0 {
+		return []string{symbol}
+	}
 	return symbols
 }
 
+// isPluralInitialism reports whether the rest of an identifier is the s
+// that makes an initialism plural, as in IDs or URLsByHost.
+func isPluralInitialism(rest []rune) bool {
+	return rest[0] == 's' && (len(rest) == 1 || !unicode.IsLower(rest[1]))
+}
+
+// spellInitialism spells a known initialism letter by letter, keeping its
+// case, so that HTTP is read as H T T P. A trailing lowercase s is kept
+// with the last letter, as in I Ds.
+func spellInitialism(word string) ([]string, bool) {
+	plural := false
+	if len(word) > 2 && strings.HasSuffix(word, "s") && strings.ToUpper(word[:len(word)-1]) == word[:len(word)-1] {
+		word = word[:len(word)-1]
+		plural = true
+	}
+	if !initialisms[strings.ToUpper(word)] {
+		return nil, false
+	}
+	letters := []string{}
+	for _, ch := range word {
+		letters = append(letters, string(ch))
+	}
+	if plural {
+		letters[len(letters)-1] += "s"
+	}
+	return letters, true
+}
+
 func (gsp *goSpeaker) speakSymbol(symbol string) {
-	gsp.speak(symbolToSpeech(symbol))
+	gsp.speak(gsp.symbolToSpeech(symbol))
 }
 
 func (gsp *goSpeaker) speakString(s string) {
 	if strings.HasPrefix(s, "\"") && strings.HasSuffix(s, "\"") {
 		s = s[1 : len(s)-1]
-		s = strings.Replace(s, "\\", " backslash ", -1)
 		if len(s) == 0 {
-			gsp.speak("empty string")
+			gsp.speakPhrase("BasicLit empty string")
 		} else if len(strings.TrimSpace(s)) == 0 {
-			if len(s) == 1 {
-				gsp.speak("string with one blank")
-			} else {
-				gsp.speak(fmt.Sprintf("string of %d blanks", len(s)))
-			}
+			gsp.speakPhrase("BasicLit blanks", "Count", len(s))
 		} else {
-			gsp.speak(s)
+			gsp.speakInterpreted(s)
 		}
+	} else if strings.HasPrefix(s, "`") && strings.HasSuffix(s, "`") {
+		gsp.speakRawString(s)
 	} else {
 		gsp.speak(s)
 	}
 }
 
-func translateSymbols(symbols []string) []string {
+func (gsp *goSpeaker) translateSymbols(symbols []string) []string {
 	newSyms := []string{}
 	for _, sym := range symbols {
-		newSym, ok := symbolTranslations[strings.ToLower(sym)]
+		newSym, ok := gsp.lookupSymbol(sym)
 		if ok {
 			sym = newSym
+		} else if letters, ok := spellInitialism(sym); ok {
+			// The letters are read as letters, so the A of API isn't "eigh"
+			newSyms = append(newSyms, letters...)
+			continue
 		}
 		newSyms = append(newSyms, sym)
 	}
 	return newSyms
 }
 
-func (gsp *goSpeaker) speak(speech string) {
-	if gsp.verboseOutput {
-		fmt.Printf("Saying: %s\n", speech)
+// lookupSymbol finds the spoken form of a symbol, preferring the lexicon
+// over the built-in translations.
+func (gsp *goSpeaker) lookupSymbol(sym string) (string, bool) {
+	key := strings.ToLower(sym)
+	if speech, ok := gsp.lexicon[key]; ok {
+		return speech, true
 	}
-	gsp.speechBuffer.WriteString(speech)
-	gsp.speechBuffer.WriteString("{pause}\n")
+	speech, ok := symbolTranslations[key]
+	return speech, ok
+}
+
+func (gsp *goSpeaker) speak(speech string) {
+	gsp.tracef("Saying: %s\n", speech)
+	gsp.addEvent(gsp.makeEvent(speech))
 }
 
-func (gsp *goSpeaker) speakBuffer() {
+func (gsp *goSpeaker) addEvent(event SpeechEvent) {
+	gsp.events = append(gsp.events, event)
+}
+
+func (gsp *goSpeaker) speakBuffer() error {
+	return gsp.speakBufferContext(context.Background())
+}
+
+func (gsp *goSpeaker) speakBufferContext(ctx context.Context) error {
 	if gsp.quiet {
-		return
-	}
-	tempFile, err := ioutil.TempFile(".", "gospeech")
-	if err != nil {
-		fmt.Printf("Unable to create temp file: %+v\n", err)
-		return
+		return nil
 	}
-	tempFile.WriteString(strings.Replace(gsp.speechBuffer.String(), "{pause}", "[[slnc 200]]", -1))
-	tempFile.Close()
-	defer os.Remove(tempFile.Name())
-	var cmd *exec.Cmd
-	if gsp.audioOutputFile == "" {
-		cmd = exec.Command("/usr/bin/say", "-f", tempFile.Name())
-	} else {
-		cmd = exec.Command("/usr/bin/say", "-f", tempFile.Name(), "-o", gsp.audioOutputFile)
+	if gsp.backend == nil {
+		gsp.backend = MakeSayBackend(DefaultVoiceSettings())
 	}
+	ctx, done := gsp.play(ctx)
+	defer done()
 
-	err = cmd.Run()
-	if err != nil {
-		fmt.Printf("Unable to run say: %+v\n", err)
-		return
+	captioned := gsp.captioned && gsp.audioOutputFile != ""
+	if synth, ok := gsp.backend.(PhraseSynthesizer); ok && (captioned || gsp.hasEarconEvents()) {
+		return gsp.speakEarcons(ctx, synth)
 	}
+	if backend, ok := gsp.backend.(ContextSpeechBackend); ok {
+		return backend.SpeakContext(ctx, gsp.GetSpeechString(), gsp.audioOutputFile)
+	}
+	return gsp.backend.Speak(gsp.GetSpeechString(), gsp.audioOutputFile)
 }
 
 func (gsp *goSpeaker) speakImportSpecs(imports []*ast.ImportSpec) {
@@ -441,34 +739,42 @@ func (gsp *goSpeaker) speakImportSpecs(imports []*ast.ImportSpec) {
 		if !gsp.isInRange(imp) {
 			continue
 		}
-		symSpeech := symbolToSpeech(imp.Path.Value)
+		gsp.enterNode(imp)
+		name := ""
 		if imp.Name != nil {
-			symSpeech = symSpeech + " as " + symbolToSpeech(imp.Name.String())
+			name = gsp.symbolToSpeech(imp.Name.String())
 		}
 		if !spokeImports {
-			gsp.speak("imports")
+			gsp.speakPhrase("File imports")
 			spokeImports = true
 		}
-		gsp.speak(symSpeech)
+		gsp.speakPhrase("ImportSpec", "Path", gsp.symbolToSpeech(imp.Path.Value), "Name", name)
+		gsp.leaveNode()
 	}
 }
 
 func (gsp *goSpeaker) speakValueSpec(vs *ast.ValueSpec, specType string) {
-	if vs.Names != nil && len(vs.Names) > 1 {
-		specType = specType + "s"
-	}
+	gsp.enterNode(vs)
+	defer gsp.leaveNode()
+	gsp.speakDocComment(vs.Doc)
+	defer gsp.speakLineComment(vs.Comment)
 	if gsp.isStartInRange(vs) {
-		gsp.speak(specType)
+		gsp.speakPhrase("ValueSpec "+specType, "Count", len(vs.Names))
 	}
 	for i := range vs.Names {
 		if gsp.isInRange(vs.Names[i]) {
 			gsp.speakSymbol(vs.Names[i].String())
-			gsp.speak("of type ")
+			if vs.Type != nil || gsp.typesInfo == nil {
+				gsp.speakPhrase("ValueSpec type")
+			}
+		}
+		if vs.Type == nil {
+			gsp.speakDefinedType(vs.Names[i])
 		}
 		gsp.speakExpr(vs.Type, true)
-		if vs.Values != nil && vs.Values[i] != nil {
+		if i < len(vs.Values) && vs.Values[i] != nil {
 			if gsp.isInRange(vs.Values[i]) {
-				gsp.speak("equals")
+				gsp.speakPhrase("ValueSpec equals")
 			}
 			gsp.speakExpr(vs.Values[i], false)
 		}
@@ -476,39 +782,49 @@ func (gsp *goSpeaker) speakValueSpec(vs *ast.ValueSpec, specType string) {
 }
 
 func (gsp *goSpeaker) speakTypeSpec(ts *ast.TypeSpec) {
+	gsp.enterNode(ts)
+	defer gsp.leaveNode()
+	gsp.speakDocComment(ts.Doc)
+	defer gsp.speakLineComment(ts.Comment)
+	if gsp.isInRange(ts) {
+		gsp.speakPhrase("TypeSpec", "Name", gsp.symbolToSpeech(ts.Name.String()))
+	}
+	gsp.speakTypeParams(ts.TypeParams)
 	if gsp.isInRange(ts) {
-		gsp.speak("type")
-		gsp.speakSymbol(ts.Name.String())
-		gsp.speak("is")
+		gsp.speakPhrase("TypeSpec is")
 	}
 	gsp.speakExpr(ts.Type, true)
 }
 
 func (gsp *goSpeaker) speakDeclaration(d ast.Decl) {
+	gsp.enterNode(d)
+	defer gsp.leaveNode()
 	switch v := d.(type) {
 	case *ast.FuncDecl:
 		gsp.functionStack = append(gsp.functionStack, v.Name.String())
 
+		gsp.speakDocComment(v.Doc)
 		if gsp.isStartInRange(v) {
-			gsp.speak("function " + symbolToSpeech(v.Name.String()))
-			if gsp.verboseOutput {
-				fmt.Printf("function name: %s\n", v.Name.String())
-			}
+			gsp.speakPhrase("FuncDecl", "Name", gsp.symbolToSpeech(v.Name.String()))
+			gsp.tracef("function name: %s\n", v.Name.String())
+			gsp.speakTypeParams(v.Type.TypeParams)
 			if v.Recv != nil && v.Recv.List != nil && len(v.Recv.List) > 0 {
-				gsp.speakFieldList(v.Recv, "with", "receiver", nil)
+				gsp.speakFieldList(v.Recv, "FuncDecl receiver", nil)
 			}
 
-			gsp.speakFieldList(v.Type.Params, "taking ", "parameter", v.Type)
-			gsp.speakFieldList(v.Type.Results, "and returning ", "value", v.Type)
+			gsp.speakFieldList(v.Type.Params, "FuncType params", v.Type)
+			gsp.speakFieldList(v.Type.Results, "FuncType results", v.Type)
 		}
-		gsp.speakBlockStmt(v.Body, "function body", "end function "+symbolToSpeech(v.Name.String()))
+		gsp.speakBlockStmt(v.Body, gsp.phrase("FuncDecl start"),
+			gsp.phrase("FuncDecl end", "Name", gsp.symbolToSpeech(v.Name.String())))
 
 		gsp.functionStack = gsp.functionStack[:len(gsp.functionStack)-1]
 	case *ast.GenDecl:
+		gsp.speakDocComment(v.Doc)
 		switch v.Tok {
 		case token.CONST:
 			for _, c := range v.Specs {
-				gsp.speakValueSpec(c.(*ast.ValueSpec), "constant")
+				gsp.speakValueSpec(c.(*ast.ValueSpec), "const")
 			}
 		case token.VAR:
 			for _, v := range v.Specs {
@@ -523,31 +839,56 @@ func (gsp *goSpeaker) speakDeclaration(d ast.Decl) {
 		if !gsp.isInRange(v) {
 			return
 		}
-		badDeclText := gsp.getFileString(v.From, v.To)
-		gsp.speak("Bad declaration")
-		gsp.speakSymbol(badDeclText)
+		gsp.speakPhrase("BadDecl", "Line", gsp.fileSet.Position(v.From).Line)
 	}
 }
 
-func (gsp *goSpeaker) speakFieldList(fields *ast.FieldList, takeOrRec string, fieldType string, parent ast.Node) {
-	if fields == nil {
-		if parent != nil && gsp.isStartInRange(parent) {
-			gsp.speak(takeOrRec + " no " + fieldType + "s")
-		}
+func (gsp *goSpeaker) speakTypeParams(tparams *ast.FieldList) {
+	if tparams == nil || tparams.NumFields() == 0 {
 		return
 	}
-	if fields.NumFields() == 0 {
-		if gsp.isStartInRange(fields) {
-			gsp.speak(takeOrRec + " no " + fieldType + "s")
+	gsp.enterNode(tparams)
+	defer gsp.leaveNode()
+
+	if gsp.isStartInRange(tparams) {
+		gsp.speakPhrase("TypeParams", "Count", tparams.NumFields())
+	}
+	for i, field := range tparams.List {
+		if i > 0 && gsp.isStartInRange(field) {
+			gsp.speakPhrase("TypeParams and")
 		}
-	} else if fields.NumFields() == 1 {
-		if gsp.isStartInRange(fields) {
-			gsp.speak(takeOrRec + strconv.Itoa(fields.NumFields()) + " " + fieldType)
+		gsp.speakTypeParam(field)
+	}
+}
+
+func (gsp *goSpeaker) speakTypeParam(field *ast.Field) {
+	gsp.enterNode(field)
+	defer gsp.leaveNode()
+
+	for _, name := range field.Names {
+		if gsp.isInRange(name) {
+			gsp.speakSymbol(name.String())
 		}
-	} else {
-		if gsp.isStartInRange(fields) {
-			gsp.speak(takeOrRec + strconv.Itoa(fields.NumFields()) + " " + fieldType + "s")
+	}
+	if gsp.isInRange(field.Type) {
+		gsp.speakPhrase("TypeParam constraint", "Count", len(field.Names))
+	}
+	gsp.speakExpr(field.Type, true)
+}
+
+// speakFieldList reads a list of fields, introduced by a message that is
+// given their count.
+func (gsp *goSpeaker) speakFieldList(fields *ast.FieldList, message string, parent ast.Node) {
+	if fields == nil {
+		if parent != nil && gsp.isStartInRange(parent) {
+			gsp.speakPhrase(message, "Count", 0)
 		}
+		return
+	}
+	gsp.enterNode(fields)
+	defer gsp.leaveNode()
+	if gsp.isStartInRange(fields) {
+		gsp.speakPhrase(message, "Count", fields.NumFields())
 	}
 	if fields.List != nil {
 		for _, field := range fields.List {
@@ -557,22 +898,22 @@ func (gsp *goSpeaker) speakFieldList(fields *ast.FieldList, takeOrRec string, fi
 }
 
 func (gsp *goSpeaker) speakField(field *ast.Field) {
-	as := "as "
-	if len(field.Names) > 1 {
-		as = "all as"
-	}
+	gsp.enterNode(field)
+	defer gsp.leaveNode()
+	gsp.speakDocComment(field.Doc)
+	defer gsp.speakLineComment(field.Comment)
 	for _, fn := range field.Names {
 		if gsp.isInRange(fn) {
-			gsp.speak(symbolToSpeech(fn.String()))
+			gsp.speak(gsp.symbolToSpeech(fn.String()))
 		}
 	}
 	if gsp.isInRange(field.Type) {
-		gsp.speak(as)
+		gsp.speakPhrase("Field type", "Count", len(field.Names))
 		gsp.speakExpr(field.Type, true)
 	}
 	if field.Tag != nil {
 		if gsp.isInRange(field.Tag) {
-			gsp.speak("with tag")
+			gsp.speakPhrase("Field tag")
 		}
 		gsp.speakExpr(field.Tag, true)
 	}
@@ -582,20 +923,27 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 	if expr == nil {
 		return
 	}
+	gsp.enterNode(expr)
+	defer gsp.leaveNode()
+	if gsp.speakNodeTemplate(expr, isDecl) {
+		return
+	}
 	switch v := expr.(type) {
 	case *ast.Ident:
 		if gsp.isInRange(v) {
-			gsp.speak(symbolToSpeech(v.String()))
+			gsp.speak(gsp.symbolToSpeech(v.String()))
 		}
 	case *ast.ArrayType:
 		if gsp.isInRange(v) {
 			if v.Len == nil {
-				gsp.speak("slice of")
+				gsp.speakPhrase("ArrayType slice")
 			} else {
+				if gsp.isStartInRange(v) {
+					gsp.speakPhrase("ArrayType")
+				}
 				gsp.speakExpr(v.Len, isDecl)
 				if gsp.isEndInRange(v.Len) {
-					gsp.speak("element")
-					gsp.speak("array of")
+					gsp.speakPhrase("ArrayType length")
 				}
 			}
 		}
@@ -603,49 +951,57 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 	case *ast.StarExpr:
 		if gsp.isInRange(v) {
 			if isDecl {
-				gsp.speak("pointer to")
+				gsp.speakPhrase("StarExpr type")
 			} else {
-				gsp.speak("contents of ")
+				gsp.speakPhrase("StarExpr")
 			}
 		}
 		gsp.speakExpr(v.X, isDecl)
 	case *ast.MapType:
 		if gsp.isStartInRange(v) {
-			gsp.speak("map")
+			gsp.speakPhrase("MapType")
 		}
 		if gsp.isStartInRange(v.Key) {
-			gsp.speak("with ")
+			gsp.speakPhrase("MapType key start")
 		}
 		gsp.speakExpr(v.Key, isDecl)
 		if gsp.isEndInRange(v.Key) {
-			gsp.speak("key")
+			gsp.speakPhrase("MapType key")
 		}
 		if gsp.isStartInRange(v.Value) {
-			gsp.speak("and ")
+			gsp.speakPhrase("MapType value start")
 		}
 		gsp.speakExpr(v.Value, isDecl)
 		if gsp.isEndInRange(v.Value) {
-			gsp.speak("value")
+			gsp.speakPhrase("MapType value")
 		}
 	case *ast.SelectorExpr:
+		if !isDecl && gsp.speakTypedSelector(v, false) {
+			return
+		}
 		gsp.speakExpr(v.X, isDecl)
 		if gsp.isInRange(v.Sel) {
-			gsp.speak("dot")
+			gsp.speakPhrase("SelectorExpr")
 		}
 		gsp.speakExpr(v.Sel, isDecl)
 	case *ast.BinaryExpr:
 		gsp.speakExpr(v.X, isDecl)
 		if gsp.isPosInRange(v.OpPos) {
-			gsp.speakBinaryOp(v.Op.String())
+			if isDecl && v.Op == token.OR {
+				// In a type constraint | joins the terms of a union
+				gsp.speakPhrase("BinaryExpr union")
+			} else {
+				gsp.speakBinaryOp(v.Op.String())
+			}
 		}
 		gsp.speakExpr(v.Y, isDecl)
 	case *ast.ParenExpr:
 		if gsp.isPosInRange(v.Lparen) {
-			gsp.speak("left paren")
+			gsp.speakPhrase("ParenExpr start")
 		}
 		gsp.speakExpr(v.X, isDecl)
 		if gsp.isPosInRange(v.Rparen) {
-			gsp.speak("right paren")
+			gsp.speakPhrase("ParenExpr end")
 		}
 	case *ast.CallExpr:
 		gsp.speakFunctionCall(v)
@@ -656,39 +1012,39 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 		gsp.speakExpr(v.X, isDecl)
 	case *ast.BasicLit:
 		if gsp.isStartInRange(v) {
-			gsp.speakString(v.Value)
+			gsp.speakLiteral(v)
 		}
 	case *ast.SliceExpr:
 		if gsp.isStartInRange(v) {
-			gsp.speak("slice")
+			gsp.speakPhrase("SliceExpr")
 		}
 		gsp.speakExpr(v.X, isDecl)
 		if gsp.isPosInRange(v.Lbrack) {
-			gsp.speak("from")
+			gsp.speakPhrase("SliceExpr low")
 		}
 		if v.Low != nil {
 			gsp.speakExpr(v.Low, isDecl)
 		} else {
 			if gsp.isPosInRange(v.Lbrack) {
-				gsp.speak("start")
+				gsp.speakPhrase("SliceExpr no low")
 			}
 		}
 
 		if v.High != nil {
 			if gsp.isInRange(v.High) {
-				gsp.speak("to")
+				gsp.speakPhrase("SliceExpr high")
 			}
 			gsp.speakExpr(v.High, isDecl)
 		} else {
 			if !v.Slice3 && gsp.isPosInRange(v.Rbrack) {
-				gsp.speak("to end")
+				gsp.speakPhrase("SliceExpr no high")
 			} else if gsp.isPosInRange(v.Rbrack) {
-				gsp.speak("to end")
+				gsp.speakPhrase("SliceExpr no high")
 			}
 		}
 		if v.Slice3 {
 			if gsp.isInRange(v.Max) {
-				gsp.speak("with cap ")
+				gsp.speakPhrase("SliceExpr max")
 			}
 			gsp.speakExpr(v.Max, isDecl)
 		}
@@ -697,29 +1053,46 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 
 	case *ast.KeyValueExpr:
 		if gsp.isInRange(v.Key) {
-			gsp.speak("key")
+			gsp.speakPhrase("KeyValueExpr")
 		}
 		gsp.speakExpr(v.Key, isDecl)
 		if gsp.isInRange(v.Value) {
-			gsp.speak("with value	")
+			gsp.speakPhrase("KeyValueExpr value")
 		}
 		gsp.speakExpr(v.Value, isDecl)
 
 	case *ast.FuncLit:
 		if gsp.isStartInRange(v) {
-			gsp.speak("lambda")
+			gsp.speakPhrase("FuncLit")
 		}
-		gsp.speakFieldList(v.Type.Params, "taking", "parameter", v.Type)
-		gsp.speakFieldList(v.Type.Results, "and returning", "value", v.Type)
-		gsp.speakBlockStmt(v.Body, "is", "end lambda")
+		gsp.speakFieldList(v.Type.Params, "FuncType params", v.Type)
+		gsp.speakFieldList(v.Type.Results, "FuncType results", v.Type)
+		gsp.speakBlockStmt(v.Body, gsp.phrase("FuncLit start"), gsp.phrase("FuncLit end"))
 
 	case *ast.IndexExpr:
 		gsp.speakExpr(v.X, isDecl)
 		if gsp.isPosInRange(v.Lbrack) {
-			gsp.speak("sub")
+			if isDecl {
+				// In a type, X[T] can only be an instantiation
+				gsp.speakPhrase("IndexExpr type")
+			} else {
+				gsp.speakPhrase("IndexExpr")
+			}
 		}
 		gsp.speakExpr(v.Index, isDecl)
 
+	case *ast.IndexListExpr:
+		gsp.speakExpr(v.X, isDecl)
+		if gsp.isPosInRange(v.Lbrack) {
+			gsp.speakPhrase("IndexListExpr")
+		}
+		for i, index := range v.Indices {
+			if i > 0 && gsp.isStartInRange(index) {
+				gsp.speakPhrase("IndexListExpr and")
+			}
+			gsp.speakExpr(index, true)
+		}
+
 	case *ast.InterfaceType:
 		gsp.speakInterfaceType(v)
 
@@ -729,19 +1102,19 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 	case *ast.TypeAssertExpr:
 		gsp.speakExpr(v.X, isDecl)
 		if v.Type != nil && gsp.isStartInRange(v.Type) || (v.X != nil && gsp.isEndInRange(v.X)) {
-			gsp.speak("as type")
+			gsp.speakPhrase("TypeAssertExpr")
 		}
 		gsp.speakExpr(v.Type, false)
 
 	case *ast.ChanType:
 		if v.Dir == ast.SEND {
 			if gsp.isPosInRange(v.Arrow) {
-				gsp.speak("send to channel")
+				gsp.speakPhrase("ChanType send")
 			}
 			gsp.speakExpr(v.Value, isDecl)
 		} else {
 			if gsp.isPosInRange(v.Arrow) {
-				gsp.speak("received from channel")
+				gsp.speakPhrase("ChanType receive")
 			}
 			gsp.speakExpr(v.Value, isDecl)
 		}
@@ -749,50 +1122,50 @@ func (gsp *goSpeaker) speakExpr(expr ast.Expr, isDecl bool) {
 	case *ast.Ellipsis:
 		if v.Elt != nil {
 			if gsp.isStartInRange(v) {
-				gsp.speak("variable number of")
+				gsp.speakPhrase("Ellipsis")
 			}
 			gsp.speakExpr(v.Elt, isDecl)
 		} else {
 			if gsp.isInRange(v) {
-				gsp.speak("variable number")
+				gsp.speakPhrase("Ellipsis alone")
 			}
 		}
 
 	case *ast.FuncType:
 		if gsp.isStartInRange(v) {
-			gsp.speak("function")
+			gsp.speakPhrase("FuncType")
 		}
-		gsp.speakFieldList(v.Params, "taking ", "parameter", v)
-		gsp.speakFieldList(v.Results, "and returning ", "value", v)
+		gsp.speakFieldList(v.Params, "FuncType params", v)
+		gsp.speakFieldList(v.Results, "FuncType results", v)
 
 	case *ast.BadExpr:
-		badDeclText := gsp.getFileString(v.From, v.To)
 		if gsp.isStartInRange(v) {
-			gsp.speak("Bad expression")
+			gsp.speakPhrase("BadExpr", "Line", gsp.fileSet.Position(v.From).Line)
 		}
-		gsp.speakSymbol(badDeclText)
 	}
 }
 
 func (gsp *goSpeaker) speakCompositeLit(c *ast.CompositeLit, isDecl bool) {
 	if len(c.Elts) == 0 {
 		if gsp.isStartInRange(c) {
-			gsp.speak("empty")
+			gsp.speakPhrase("CompositeLit empty")
 		}
 	}
 	if c.Type != nil {
 		gsp.speakExpr(c.Type, isDecl)
 	}
-	if len(c.Elts) > 0 {
-		if gsp.isPosInRange(c.Lbrace) {
-			gsp.speak("containing")
+	if len(c.Elts) == 0 {
+		if gsp.isEndInRange(c) {
+			gsp.speakPhrase("CompositeLit empty after")
 		}
+	} else if gsp.isPosInRange(c.Lbrace) {
+		gsp.speakPhrase("CompositeLit elements")
 	}
 	first := true
 	for _, e := range c.Elts {
 		if !first {
 			if gsp.isStartInRange(e) {
-				gsp.speak("comma")
+				gsp.speakPhrase("CompositeLit comma")
 			}
 		} else {
 			first = false
@@ -804,130 +1177,174 @@ func (gsp *goSpeaker) speakCompositeLit(c *ast.CompositeLit, isDecl bool) {
 func (gsp *goSpeaker) speakInterfaceType(iface *ast.InterfaceType) {
 	if iface.Methods == nil || iface.Methods.List == nil || len(iface.Methods.List) == 0 {
 		if gsp.isInRange(iface) {
-			gsp.speak("empty interface")
+			gsp.speakPhrase("InterfaceType empty")
 		}
-	} else {
-		if gsp.isInRange(iface) {
-			gsp.speak("interface")
+		return
+	}
+	if gsp.isInRange(iface) {
+		gsp.speakPhrase("InterfaceType")
+	}
+
+	methods := []*ast.Field{}
+	for _, field := range iface.Methods.List {
+		if len(field.Names) > 0 {
+			methods = append(methods, field)
+			continue
 		}
-		gsp.speakFieldList(iface.Methods, "having", "method", iface)
+		gsp.enterNode(field)
+		if gsp.isStartInRange(field) {
+			if isTypeSetTerm(field.Type) {
+				gsp.speakPhrase("InterfaceType type set")
+			} else {
+				gsp.speakPhrase("InterfaceType embedded")
+			}
+		}
+		gsp.speakExpr(field.Type, true)
+		gsp.leaveNode()
+	}
+
+	if len(methods) > 0 {
+		gsp.speakFieldList(&ast.FieldList{
+			Opening: iface.Methods.Opening,
+			List:    methods,
+			Closing: iface.Methods.Closing,
+		}, "InterfaceType methods", iface)
+	}
+}
+
+// isTypeSetTerm reports whether an embedded interface element is a union or
+// a ~T term rather than an embedded interface.
+func isTypeSetTerm(expr ast.Expr) bool {
+	switch v := expr.(type) {
+	case *ast.BinaryExpr:
+		return v.Op == token.OR
+	case *ast.UnaryExpr:
+		return v.Op == token.TILDE
 	}
+	return false
 }
 
 func (gsp *goSpeaker) speakStructType(s *ast.StructType) {
 	if s.Fields == nil || s.Fields.List == nil || len(s.Fields.List) == 0 {
 		if gsp.isStartInRange(s) {
-			gsp.speak("empty struct")
+			gsp.speakPhrase("StructType empty")
 		}
 	} else {
 		if gsp.isStartInRange(s) {
-			gsp.speak("struct")
+			gsp.speakPhrase("StructType")
 		}
-		gsp.speakFieldList(s.Fields, "having", "field", s)
+		gsp.speakFieldList(s.Fields, "StructType fields", s)
 	}
 }
 
 func (gsp *goSpeaker) speakFunctionCall(c *ast.CallExpr) {
 	if len(c.Args) == 0 {
 		if gsp.isStartInRange(c) {
-			gsp.speak("call")
+			gsp.speakPhrase("CallExpr no args")
 		}
 	}
-	gsp.speakExpr(c.Fun, false)
+	if sel, ok := c.Fun.(*ast.SelectorExpr); ok && gsp.typesInfo != nil {
+		gsp.enterNode(sel)
+		if !gsp.speakTypedSelector(sel, true) {
+			gsp.speakExpr(sel.X, false)
+			if gsp.isInRange(sel.Sel) {
+				gsp.speakPhrase("SelectorExpr")
+			}
+			gsp.speakExpr(sel.Sel, false)
+		}
+		gsp.leaveNode()
+	} else {
+		gsp.speakExpr(c.Fun, false)
+	}
 	if len(c.Args) > 0 {
 		if gsp.isPosInRange(c.Lparen) {
-			gsp.speak("of")
+			gsp.speakPhrase("CallExpr")
 		}
 	}
-	spokeEllipsis := false
 	first := true
 	for _, a := range c.Args {
 		if !first {
 			if gsp.isStartInRange(a) {
-				gsp.speak("comma	")
+				gsp.speakPhrase("CallExpr comma")
 			}
 		} else {
 			first = false
 		}
-		if c.Ellipsis != token.NoPos && c.Ellipsis < a.Pos() && !spokeEllipsis {
-			if gsp.isPosInRange(c.Ellipsis) {
-				gsp.speak("ellipsis")
-			}
-			spokeEllipsis = true
-		}
 		gsp.speakExpr(a, false)
 	}
-}
-
-var binaryOpSpeech = map[string]string{
-	"||": "or",
-	"&&": "and",
-	"==": "equals",
-	"!=": "does not equal",
-	"<":  "is less than",
-	"<=": "is less than or equal to",
-	">":  "is greater than",
-	">=": "is greater than or equal to",
-	"+":  "plus",
-	"-":  "minus",
-	"|":  "bitwise or",
-	"^":  "exclusive or",
-	"*":  "times",
-	"/":  "divided by",
-	"%":  "modulo",
-	"<<": "shifted left by",
-	">>": "shifted right by",
-	"&":  "bitwise and",
-	"&^": "bitwise and not",
-}
-
-func (gsp *goSpeaker) speakBinaryOp(op string) {
-	speechVal, ok := binaryOpSpeech[op]
-	if ok {
-		gsp.speak(speechVal)
+	if c.Ellipsis != token.NoPos && gsp.isPosInRange(c.Ellipsis) {
+		gsp.speakPhrase("CallExpr ellipsis")
 	}
 }
 
-var unaryOpSpeech = map[string]string{
-	"+":  "positive",
-	"-":  "negative",
-	"!":  "not",
-	"^":  "bitwise not",
-	"*":  "star",
-	"&":  "ref",
-	"<-": "receive from channel",
+func (gsp *goSpeaker) speakBinaryOp(op string) {
+	gsp.speakPhrase("BinaryExpr " + op)
 }
 
 func (gsp *goSpeaker) speakUnaryOp(op string) {
-	speechVal, ok := unaryOpSpeech[op]
-	if ok {
-		gsp.speak(speechVal)
-	}
+	gsp.speakPhrase("UnaryExpr " + op)
 }
 
 func (gsp *goSpeaker) speakBlockStmt(stmts *ast.BlockStmt, bodyStart string, bodyEnd string) {
+	if stmts == nil {
+		return
+	}
+	// The block belongs to the current node, which chooses its earcons
+	kind := nodeKind(gsp.currentNode())
+	startKey := kind + " start"
+	if ifStmt, ok := gsp.currentNode().(*ast.IfStmt); ok && ifStmt.Else == ast.Stmt(stmts) {
+		startKey = "IfStmt else"
+	}
+	gsp.enterNode(stmts)
+	defer gsp.leaveNode()
 	if gsp.isStartInRange(stmts) {
-		gsp.speak(bodyStart)
+		gsp.speakMarker(startKey, bodyStart)
 	}
-	for _, bs := range stmts.List {
-		gsp.speakStmt(bs)
+	gsp.depth++
+	gsp.speakStmtList(stmts.List, stmts.Rbrace)
+	gsp.depth--
+	if gsp.isEndInRange(stmts) && !gsp.shallow {
+		gsp.speakMarker(kind+" end", bodyEnd)
 	}
-	if gsp.isEndInRange(stmts) {
-		gsp.speak(bodyEnd)
+}
+
+// speakStmtList reads the statements of a block. When speaking shallowly it
+// only says how many statements there are.
+func (gsp *goSpeaker) speakStmtList(stmts []ast.Stmt, end token.Pos) {
+	if gsp.shallow {
+		gsp.speakPhrase("BlockStmt count", "Count", len(stmts))
+		return
 	}
+	for i, bs := range stmts {
+		if i > 0 && gsp.isStartInRange(bs) {
+			gsp.speakSeparator()
+		}
+		gsp.speakCommentsBefore(bs.Pos())
+		gsp.speakStmt(bs)
+	}
+	gsp.speakCommentsBefore(end)
 }
 
 func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
+	if stmt == nil {
+		return
+	}
+	gsp.enterNode(stmt)
+	defer gsp.leaveNode()
+	if gsp.speakNodeTemplate(stmt, false) {
+		return
+	}
+
 	switch v := stmt.(type) {
 	case *ast.BlockStmt:
 		if gsp.isInRange(stmt) {
-			gsp.speak("begin block")
+			gsp.speakMarker("BlockStmt start", gsp.phrase("BlockStmt start"))
 		}
-		for _, bs := range v.List {
-			gsp.speakStmt(bs)
-		}
-		if gsp.isInRange(stmt) {
-			gsp.speak("end block")
+		gsp.depth++
+		gsp.speakStmtList(v.List, v.Rbrace)
+		gsp.depth--
+		if gsp.isInRange(stmt) && !gsp.shallow {
+			gsp.speakMarker("BlockStmt end", gsp.phrase("BlockStmt end"))
 		}
 	case *ast.IfStmt:
 		gsp.speakIfStatement(v)
@@ -935,43 +1352,43 @@ func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
 		gsp.speakForLoop(v)
 	case *ast.RangeStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("range over ")
+			gsp.speakPhrase("RangeStmt")
 		}
 		gsp.speakExpr(v.X, false)
 		if (v.Key != nil && gsp.isStartInRange(v.Key)) || (v.Key == nil && v.Value != nil &&
 			gsp.isStartInRange(v.Value)) {
-			gsp.speak("with")
+			gsp.speakPhrase("RangeStmt with")
 		}
 		if v.Key != nil {
 			if gsp.isStartInRange(v.Key) {
-				gsp.speak("key")
+				gsp.speakPhrase("RangeStmt key")
 			}
 			gsp.speakExpr(v.Key, false)
 			if v.Value != nil {
 				if gsp.isStartInRange(v.Value) {
-					gsp.speak("and")
+					gsp.speakPhrase("RangeStmt and")
 				}
 			}
 		}
 		if v.Value != nil {
 			if gsp.isInRange(v.Value) {
-				gsp.speak("value")
+				gsp.speakPhrase("RangeStmt value")
 			}
 			gsp.speakExpr(v.Value, false)
 		}
 		if v.Body != nil {
-			gsp.speakBlockStmt(v.Body, "range body", "end range")
+			gsp.speakBlockStmt(v.Body, gsp.phrase("RangeStmt start"), gsp.phrase("RangeStmt end"))
 		}
 	case *ast.ReturnStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("return")
+			gsp.speakPhrase("ReturnStmt")
 		}
 
 		first := true
 		for _, e := range v.Results {
 			if !first {
 				if gsp.isStartInRange(e) {
-					gsp.speak("also")
+					gsp.speakPhrase("ReturnStmt also")
 				}
 			} else {
 				first = false
@@ -986,11 +1403,11 @@ func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
 
 	case *ast.BranchStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak(v.Tok.String())
+			gsp.speakPhrase("BranchStmt " + v.Tok.String())
 		}
 		if v.Label != nil {
 			if gsp.isInRange(v.Label) {
-				gsp.speak("at")
+				gsp.speakPhrase("BranchStmt label")
 				gsp.speakSymbol(v.Label.String())
 			}
 		}
@@ -1008,35 +1425,30 @@ func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
 
 	case *ast.DeferStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("defer")
+			gsp.speakPhrase("DeferStmt")
 		}
 		gsp.speakExpr(v.Call, false)
 
 	case *ast.GoStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("go")
+			gsp.speakPhrase("GoStmt")
 		}
 		gsp.speakExpr(v.Call, false)
 
 	case *ast.EmptyStmt:
 		if gsp.isInRange(v) {
-			gsp.speak("empty")
+			gsp.speakPhrase("EmptyStmt")
 		}
 
 	case *ast.IncDecStmt:
 		if gsp.isStartInRange(v) {
-			if v.Tok == token.INC {
-				gsp.speak("increment")
-			} else {
-				gsp.speak("decrement")
-			}
+			gsp.speakPhrase("IncDecStmt " + v.Tok.String())
 		}
 		gsp.speakExpr(v.X, false)
 
 	case *ast.LabeledStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("label")
-			gsp.speakSymbol(v.Label.String())
+			gsp.speakPhrase("LabeledStmt", "Name", gsp.symbolToSpeech(v.Label.String()))
 		}
 		gsp.speakStmt(v.Stmt)
 
@@ -1045,20 +1457,18 @@ func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
 
 	case *ast.SendStmt:
 		if gsp.isStartInRange(v) {
-			gsp.speak("send")
+			gsp.speakPhrase("SendStmt")
 		}
 		gsp.speakExpr(v.Value, false)
 		if gsp.isInRange(v.Chan) {
-			gsp.speak("to channel")
+			gsp.speakPhrase("SendStmt channel")
 		}
 		gsp.speakExpr(v.Chan, false)
 
 	case *ast.BadStmt:
-		badDeclText := gsp.getFileString(v.From, v.To)
 		if gsp.isStartInRange(v) {
-			gsp.speak("Bad statement")
+			gsp.speakPhrase("BadStmt", "Line", gsp.fileSet.Position(v.From).Line)
 		}
-		gsp.speakSymbol(badDeclText)
 
 	case *ast.DeclStmt:
 		gsp.speakDeclaration(v.Decl)
@@ -1067,13 +1477,21 @@ func (gsp *goSpeaker) speakStmt(stmt ast.Stmt) {
 
 func (gsp *goSpeaker) speakAssignStatement(s *ast.AssignStmt) {
 	if gsp.isStartInRange(s) {
-		gsp.speak("let")
+		gsp.speakPhrase("AssignStmt")
+	}
+	speakEqual := func() {
+		if operator := gsp.assignOperatorSpeech(s.Tok); operator != "" {
+			gsp.speakPhrase("AssignStmt operator", "Operator", operator)
+		} else {
+			gsp.speakPhrase("AssignStmt equal")
+		}
 	}
 	if len(s.Lhs) > 1 && len(s.Lhs) == len(s.Rhs) {
 		for i := range s.Lhs {
 			gsp.speakExpr(s.Lhs[i], false)
+			gsp.speakDefinedType(s.Lhs[i])
 			if gsp.isEndInRange(s.Lhs[i]) {
-				gsp.speak("equal")
+				speakEqual()
 			}
 			gsp.speakExpr(s.Rhs[i], false)
 		}
@@ -1082,15 +1500,16 @@ func (gsp *goSpeaker) speakAssignStatement(s *ast.AssignStmt) {
 		for _, l := range s.Lhs {
 			if !first {
 				if gsp.isStartInRange(l) {
-					gsp.speak("and")
+					gsp.speakPhrase("AssignStmt and")
 				}
 			} else {
 				first = false
 			}
 			gsp.speakExpr(l, false)
+			gsp.speakDefinedType(l)
 		}
 		if len(s.Rhs) > 0 && gsp.isStartInRange(s.Rhs[0]) {
-			gsp.speak("equal")
+			speakEqual()
 		}
 		for _, r := range s.Rhs {
 			gsp.speakExpr(r, false)
@@ -1098,65 +1517,75 @@ func (gsp *goSpeaker) speakAssignStatement(s *ast.AssignStmt) {
 	}
 }
 
+// assignOperatorSpeech speaks the operator of an assignment such as +=,
+// or returns an empty string for = and :=.
+func (gsp *goSpeaker) assignOperatorSpeech(tok token.Token) string {
+	op := strings.TrimSuffix(tok.String(), "=")
+	if op == "" || op == ":" {
+		return ""
+	}
+	return gsp.phrase("BinaryExpr " + op)
+}
+
 func (gsp *goSpeaker) speakIfStatement(s *ast.IfStmt) {
 	if gsp.isStartInRange(s) {
-		gsp.speak("if")
+		gsp.speakPhrase("IfStmt")
 	}
 	if s.Init != nil {
 		if gsp.isStartInRange(s.Init) {
-			gsp.speak("with initializer ")
+			gsp.speakPhrase("IfStmt init")
 		}
 		gsp.speakStmt(s.Init)
 		if gsp.isEndInRange(s.Init) {
-			gsp.speak("when")
+			gsp.speakPhrase("IfStmt cond")
 		}
 	}
 	if s.Cond != nil {
 		gsp.speakExpr(s.Cond, false)
 	}
 	if s.Body != nil {
-		bodyEnd := "end if"
+		bodyEnd := gsp.phrase("IfStmt end")
 		if s.Else != nil {
 			bodyEnd = ""
 		}
-		gsp.speakBlockStmt(s.Body, "then", bodyEnd)
+		gsp.speakBlockStmt(s.Body, gsp.phrase("IfStmt start"), bodyEnd)
 	}
 	if s.Else != nil {
 		switch e := s.Else.(type) {
 		case *ast.BlockStmt:
-			gsp.speakBlockStmt(e, "else", "end if")
+			gsp.speakBlockStmt(e, gsp.phrase("IfStmt else"), gsp.phrase("IfStmt end"))
 		default:
 			if e != nil && gsp.isStartInRange(e) {
-				gsp.speak("else")
+				gsp.speakMarker("IfStmt else", gsp.phrase("IfStmt else"))
 			}
 			gsp.speakStmt(e)
 		}
 	}
 }
 func (gsp *goSpeaker) speakForLoop(fl *ast.ForStmt) {
-	loopType := "for"
+	while := false
 	if fl.Init == nil && fl.Post == nil {
 		if fl.Cond == nil {
 			if gsp.isStartInRange(fl) {
-				gsp.speak("for ever")
+				gsp.speakPhrase("ForStmt forever")
 			}
 		} else {
 			if gsp.isStartInRange(fl) {
-				gsp.speak("while")
+				gsp.speakPhrase("ForStmt while")
 			}
-			loopType = "while"
+			while = true
 			gsp.speakExpr(fl.Cond, false)
 		}
 	} else {
 		if gsp.isStartInRange(fl) {
-			gsp.speak("for")
+			gsp.speakPhrase("ForStmt")
 		}
-		if fl.Init == nil {
+		if fl.Init != nil {
 			gsp.speakStmt(fl.Init)
 		}
 		if fl.Cond != nil {
 			if gsp.isStartInRange(fl.Cond) {
-				gsp.speak("while")
+				gsp.speakPhrase("ForStmt cond")
 			}
 			gsp.speakExpr(fl.Cond, false)
 		}
@@ -1164,88 +1593,84 @@ func (gsp *goSpeaker) speakForLoop(fl *ast.ForStmt) {
 			gsp.speakStmt(fl.Post)
 		}
 	}
-	gsp.speakBlockStmt(fl.Body, "do", "end "+loopType+" loop")
+	gsp.speakBlockStmt(fl.Body, gsp.phrase("ForStmt start"), gsp.phrase("ForStmt end", "While", while))
 }
 
 func (gsp *goSpeaker) speakSwitchStatement(s *ast.SwitchStmt) {
 	if gsp.isStartInRange(s) {
-		gsp.speak("switch")
+		gsp.speakPhrase("SwitchStmt")
 	}
 	if s.Init != nil {
 		if gsp.isStartInRange(s.Init) {
-			gsp.speak("with initializer")
+			gsp.speakPhrase("SwitchStmt init")
 		}
 		gsp.speakStmt(s.Init)
 	}
 	if s.Tag != nil && gsp.isStartInRange(s.Tag) {
-		gsp.speak("on")
+		gsp.speakPhrase("SwitchStmt tag")
 	}
 	gsp.speakExpr(s.Tag, false)
-	gsp.speakBlockStmt(s.Body, "", "end switch")
+	gsp.speakBlockStmt(s.Body, "", gsp.phrase("SwitchStmt end"))
 
 }
 
 func (gsp *goSpeaker) speakTypeSwitchStatement(s *ast.TypeSwitchStmt) {
 	if gsp.isStartInRange(s) {
-		gsp.speak("switch")
+		gsp.speakPhrase("TypeSwitchStmt")
 	}
 	if s.Init != nil {
 		if gsp.isStartInRange(s.Init) {
-			gsp.speak("with initializer")
+			gsp.speakPhrase("TypeSwitchStmt init")
 		}
 		gsp.speakStmt(s.Init)
 	}
 
 	if gsp.isStartInRange(s.Assign) {
-		gsp.speak("on type")
+		gsp.speakPhrase("TypeSwitchStmt assign")
 	}
 	gsp.speakStmt(s.Assign)
-	gsp.speakBlockStmt(s.Body, "", "end type switch")
+	gsp.speakBlockStmt(s.Body, "", gsp.phrase("TypeSwitchStmt end"))
 
 }
 
 func (gsp *goSpeaker) speakCommClause(c *ast.CommClause) {
 	if gsp.isStartInRange(c) {
 		if c.Comm != nil {
-			gsp.speak("case")
+			gsp.speakPhrase("CommClause")
 		} else {
-			gsp.speak("default")
+			gsp.speakPhrase("CommClause default")
 		}
 	}
 	gsp.speakStmt(c.Comm)
-	for _, cs := range c.Body {
-		gsp.speakStmt(cs)
-	}
+	gsp.speakStmtList(c.Body, token.NoPos)
 }
 
 func (gsp *goSpeaker) speakSwitchCase(c *ast.CaseClause) {
 	if gsp.isStartInRange(c) {
 		if len(c.List) == 0 {
-			gsp.speak("default")
+			gsp.speakPhrase("CaseClause default")
 		} else {
-			gsp.speak("case")
+			gsp.speakPhrase("CaseClause")
 		}
 	}
 	first := true
 	for _, e := range c.List {
 		if !first {
 			if gsp.isStartInRange(e) {
-				gsp.speak("or")
+				gsp.speakPhrase("CaseClause or")
 			}
 		} else {
 			first = false
 		}
 		gsp.speakExpr(e, false)
 	}
-	for _, cs := range c.Body {
-		gsp.speakStmt(cs)
-	}
+	gsp.speakStmtList(c.Body, token.NoPos)
 }
 
 func (gsp *goSpeaker) speakSelectStatement(s *ast.SelectStmt) {
 	if gsp.isStartInRange(s) {
-		gsp.speak("select")
+		gsp.speakPhrase("SelectStmt")
 	}
-	gsp.speakBlockStmt(s.Body, "", "end select")
+	gsp.speakBlockStmt(s.Body, "", gsp.phrase("SelectStmt end"))
 
 }
@@ -1,8 +1,23 @@
 package gospeak
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"go/ast"
+	"go/parser"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"text/template"
+	"time"
 )
 
 func TestHelloWorld(t *testing.T) {
@@ -19,18 +34,20 @@ func main() {
 		quiet:         true,
 		verboseOutput: false,
 		skipImports:   false,
+		startLine:     -1,
+		endLine:       -1,
 	}
 
 	goSpeaker.SpeakGoString(prog)
 
-	speechCommands := stripNewlines(stripPause(goSpeaker.speechBuffer.String()))
+	speechCommands := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
 
 	target := `package main
 imports fumt
 declarations
 function main taking no parameters and returning no values
 function body
-fumt dot Printf of Hello World! backslash n
+fumt dot print f of Hello World! newline
 end function main `
 
 	splits := splitCommands(speechCommands)
@@ -62,11 +79,13 @@ var foo int
 		quiet:         true,
 		verboseOutput: false,
 		skipImports:   false,
+		startLine:     -1,
+		endLine:       -1,
 	}
 
 	goSpeaker.SpeakGoString(prog)
 
-	speechCommands := stripNewlines(stripPause(goSpeaker.speechBuffer.String()))
+	speechCommands := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
 
 	target := "var foo of type int"
 
@@ -89,11 +108,13 @@ var foo interface{}
 		quiet:         true,
 		verboseOutput: false,
 		skipImports:   false,
+		startLine:     -1,
+		endLine:       -1,
 	}
 
 	goSpeaker.SpeakGoString(prog)
 
-	speechCommands := stripNewlines(stripPause(goSpeaker.speechBuffer.String()))
+	speechCommands := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
 
 	target := "var foo of type empty interface"
 
@@ -121,7 +142,7 @@ func splitCommands(s string) []string {
 }
 
 func stripPause(s string) string {
-	return strings.Replace(s, "{pause}", " ", -1)
+	return strings.Replace(stripMarkers(s), "{pause}", " ", -1)
 }
 
 func stripNewlines(s string) string {
@@ -148,3 +169,1438 @@ func hasSubsequence(commands, sub []string) bool {
 	}
 	return false
 }
+
+func TestUnknownBackend(t *testing.T) {
+	if _, err := MakeSpeechBackend("no-such-engine", DefaultVoiceSettings()); err == nil {
+		t.Errorf("Expected an error for an unknown backend\n")
+	}
+	if _, err := MakeSpeechBackend("say", DefaultVoiceSettings()); err != nil {
+		t.Errorf("Unexpected error creating say backend: %+v\n", err)
+	}
+}
+
+func TestESpeakMarkup(t *testing.T) {
+	markup := espeakMarkup("if{pause}\nx is less than y{pause}\nfoo & bar{pause}\n")
+
+	target := `<speak>
+if <break time="200ms"/>
+x is less than y <break time="200ms"/>
+foo &amp; bar <break time="200ms"/>
+</speak>
+`
+	if markup != target {
+		t.Errorf("Unexpected espeak markup:\n%s\n", markup)
+	}
+}
+
+func TestSSMLDocument(t *testing.T) {
+	prog := `
+package main
+
+func main() {
+	return
+}
+
+var greeting = "a < b"
+`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	doc := RenderSSML(goSpeaker.GetSpeechString(), DefaultVoiceSettings())
+
+	for _, target := range []string{
+		`<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="en-US">`,
+		`<emphasis>function</emphasis> main <break time="200ms"/>`,
+		`<emphasis>return</emphasis> <break time="200ms"/>`,
+		`<emphasis>end function</emphasis> main <break time="200ms"/>`,
+		`<say-as interpret-as="text">a &lt; b</say-as>`,
+	} {
+		if !strings.Contains(doc, target) {
+			t.Errorf("SSML document is missing %s:\n%s\n", target, doc)
+		}
+	}
+
+	var parsed struct{}
+	if err := xml.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Errorf("SSML document is not well-formed: %+v\n", err)
+	}
+
+	// The language comes from the locale, and English keywords aren't
+	// emphasized in German
+	voice := DefaultVoiceSettings()
+	voice.Language = germanLocale.Language()
+	goSpeaker.SetLocale(germanLocale)
+	goSpeaker.SpeakGoString(prog)
+	doc = RenderSSML(goSpeaker.GetSpeechString(), voice)
+	if !strings.Contains(doc, `xml:lang="de-DE"`) || strings.Contains(doc, "<emphasis>") {
+		t.Errorf("Expected a German SSML document without emphasis:\n%s\n", doc)
+	}
+	if args := MakeESpeakBackend(voice).(*espeakBackend).voiceArgs(); strings.Join(args, " ") != "-v de" {
+		t.Errorf("Expected the German espeak-ng voice, got %v\n", args)
+	}
+}
+
+func TestSpeechEvents(t *testing.T) {
+	prog := `package main
+
+func main() {
+	if x {
+		return
+	}
+}`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	var ret *SpeechEvent
+	for i, e := range goSpeaker.SpeechEvents() {
+		if e.Phrase == "return" {
+			ret = &goSpeaker.SpeechEvents()[i]
+		}
+	}
+	if ret == nil {
+		t.Errorf("No event for return statement\n")
+		return
+	}
+	if ret.Kind != "ReturnStmt" || ret.Start.Line != 5 || ret.Start.Column != 3 || ret.Depth != 2 {
+		t.Errorf("Unexpected return event: %+v\n", *ret)
+	}
+}
+
+func TestSpeechJSON(t *testing.T) {
+	prog := `package main
+
+func main() {
+	return
+}`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	var buff bytes.Buffer
+	if err := WriteSpeechJSON(&buff, goSpeaker.SpeechEvents()); err != nil {
+		t.Errorf("Unable to write JSON: %+v\n", err)
+		return
+	}
+
+	var phrases []map[string]interface{}
+	if err := json.Unmarshal(buff.Bytes(), &phrases); err != nil {
+		t.Errorf("Unable to parse JSON: %+v\n", err)
+		return
+	}
+
+	found := false
+	for _, p := range phrases {
+		if p["speech"] == "return" {
+			found = true
+			if p["line"] != 4.0 || p["column"] != 2.0 || p["function"] != "main" || p["file"] != "buffer" {
+				t.Errorf("Unexpected return phrase: %+v\n", p)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("No return phrase in %s\n", buff.String())
+	}
+}
+
+func TestCaptions(t *testing.T) {
+	prog := `package main
+
+func main() {
+	return
+}`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	var buff bytes.Buffer
+	if err := goSpeaker.WriteCaptions(&buff, CaptionsSRT); err != nil {
+		t.Errorf("Unable to write captions: %+v\n", err)
+		return
+	}
+
+	target := `1
+00:00:00,000 --> 00:00:00,685
+1: package main
+package main
+
+`
+	if !strings.HasPrefix(buff.String(), target) {
+		t.Errorf("Unexpected captions:\n%s\n", buff.String())
+	}
+	if !strings.Contains(buff.String(), "4: return\nreturn\n") {
+		t.Errorf("Missing caption for return:\n%s\n", buff.String())
+	}
+
+	// Cue text stays on one line and can't be read as a timing or markup
+	captions := []Caption{{Line: 1, SourceLine: "x := `a", Phrase: "a\n\nb --> <c> & d"}}
+	for format, target := range map[CaptionFormat]string{
+		CaptionsVTT: "1: x := `a\na b --&gt; &lt;c&gt; &amp; d\n\n",
+		CaptionsSRT: "1: x := `a\na b -- > <c> & d\n\n",
+	} {
+		buff.Reset()
+		if err := WriteCaptions(&buff, format, captions); err != nil || !strings.HasSuffix(buff.String(), target) {
+			t.Errorf("Unexpected %s cue (%+v):\n%s\n", format, err, buff.String())
+		}
+	}
+
+	// Captioned audio is put together from the phrases the cues are timed with
+	wav, err := ioutil.TempFile("", "gospeak*.wav")
+	if err != nil {
+		t.Errorf("Unable to create temp file: %+v", err)
+		return
+	}
+	wav.Close()
+	defer os.Remove(wav.Name())
+	goSpeaker.quiet = false
+	goSpeaker.backend = toneTestBackend{}
+	goSpeaker.audioOutputFile = wav.Name()
+	goSpeaker.SetCaptioned(true)
+	if err := goSpeaker.speakBuffer(); err != nil {
+		t.Errorf("Unable to speak captioned audio: %+v", err)
+		return
+	}
+	captions = goSpeaker.Captions()
+	end := captions[len(captions)-1].End + phrasePause
+	if d, err := wavDuration(wav.Name()); err != nil || d != end {
+		t.Errorf("Captions end at %v, audio at %v (%+v)", end, d, err)
+	}
+}
+
+func TestWAVDuration(t *testing.T) {
+	// One second of 8kHz mono 16-bit silence.
+	header := []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1f\x00\x00\x80\x3e\x00\x00\x02\x00\x10\x00data\x80\x3e\x00\x00")
+	wav := append(header, make([]byte, 16000)...)
+
+	f, err := ioutil.TempFile("", "gospeak*.wav")
+	if err != nil {
+		t.Errorf("Unable to create temp file: %+v\n", err)
+		return
+	}
+	defer os.Remove(f.Name())
+	f.Write(wav)
+	f.Close()
+
+	d, err := wavDuration(f.Name())
+	if err != nil || d != time.Second {
+		t.Errorf("Expected one second, got %v (%+v)\n", d, err)
+	}
+}
+
+func TestComments(t *testing.T) {
+	prog := `package main
+
+// main says hello
+func main() {
+	x := 1 // one
+	// now return
+	return
+}
+`
+
+	tests := []struct {
+		mode   CommentMode
+		target string
+	}{
+		{CommentsOff, "function main taking no parameters and returning no values function body let x equal one return end function main"},
+		{CommentsDoc, "doc comment main says hello function main taking no parameters"},
+		{CommentsAll, "let x equal one comment one comment now return return end function main"},
+		{CommentsAll, "declarations doc comment main says hello function main taking no parameters"},
+	}
+
+	for _, test := range tests {
+		goSpeaker := goSpeaker{
+			quiet:       true,
+			startLine:   -1,
+			endLine:     -1,
+			commentMode: test.mode,
+		}
+
+		goSpeaker.SpeakGoString(prog)
+
+		speechCommands := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+
+		splits := splitCommands(speechCommands)
+		targetSplits := splitCommands(test.target)
+
+		if !hasSubsequence(splits, targetSplits) {
+			t.Errorf("Could not find subsequence in comment mode %d: %s\n%s\n", test.mode, test.target, speechCommands)
+		}
+	}
+}
+
+func TestGenerics(t *testing.T) {
+	prog := `package main
+
+type Number interface {
+	~int | ~string
+}
+
+type List[T any] struct {
+	items []T
+}
+
+func Map[T any, U comparable](xs []T) *List[U] {
+	return nil
+}
+
+var m = Pair[int, string]{}
+`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	speechCommands := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits := splitCommands(speechCommands)
+
+	for _, target := range []string{
+		"type Number is interface with type set underlying type int or underlying type string",
+		"type List with type parameter T constrained by any is struct",
+		"function Map with type parameters T constrained by any and U constrained by comparable taking 1 parameter",
+		"and returning 1 value as pointer to List of U",
+		"Pair of int and string",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speechCommands)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	if err := goSpeaker.SpeakAllContext(context.Background()); err != ErrNothingLoaded {
+		t.Errorf("Expected ErrNothingLoaded, got %+v\n", err)
+	}
+	if err := goSpeaker.LoadStringErr("this is not go"); err == nil {
+		t.Errorf("Expected an error loading a malformed buffer\n")
+	}
+	if err := goSpeaker.LoadFileErr("no-such-file.go"); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %+v\n", err)
+	}
+	if err := goSpeaker.SpeakGoStringErr("package main\n\nfunc main() {\n\tx :=\n}\n"); err != nil {
+		t.Errorf("Expected a partial file to be spoken, got %+v\n", err)
+	}
+
+	// The methods without an error result log it
+	var logged bytes.Buffer
+	goSpeaker.SetLogger(log.New(&logged, "", 0))
+	goSpeaker.LoadString("this is not go")
+	goSpeaker.SpeakAll()
+	if !strings.Contains(logged.String(), ErrNothingLoaded.Error()) {
+		t.Errorf("Expected ErrNothingLoaded to be logged, got %s\n", logged.String())
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	prog := `package main
+
+func main() {
+	x := 1
+	if x {
+`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	if err := goSpeaker.LoadStringErr(prog); err != nil {
+		t.Errorf("Unable to load partial file: %+v\n", err)
+		return
+	}
+	if err := goSpeaker.SpeakErrors(); err != nil {
+		t.Errorf("Unable to speak errors: %+v\n", err)
+		return
+	}
+
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	for _, target := range []string{
+		"syntax error on line 5, column 9: expected closing brace, found end of file",
+		"line 4 reads x colon equals 1",
+		"line 5 reads if x opening brace",
+	} {
+		if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+
+	// Without a package clause nothing is loaded, but the errors are read
+	if err := goSpeaker.LoadStringErr("pakage main\n\nfunc main() {\n}\n"); err == nil {
+		t.Errorf("Expected an error loading a file without a package clause\n")
+		return
+	}
+	if err := goSpeaker.SpeakErrors(); err != nil {
+		t.Errorf("Unable to speak errors without a package clause: %+v\n", err)
+		return
+	}
+	speech = stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	target := "syntax error on line 1, column 1: expected the package keyword, found pakage line 1 reads pakage main"
+	if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+		t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+	}
+}
+
+func TestNavigator(t *testing.T) {
+	prog := `package main
+
+func main() {
+	x := 1
+	if x > 0 {
+		return
+	}
+}
+
+func other() {
+}`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+
+	if err := goSpeaker.LoadStringErr(prog); err != nil {
+		t.Errorf("Unable to load: %+v\n", err)
+		return
+	}
+	nav, err := goSpeaker.Navigator()
+	if err != nil {
+		t.Errorf("Unable to navigate: %+v\n", err)
+		return
+	}
+
+	steps := []struct {
+		move   func() error
+		target string
+	}{
+		{nav.Repeat, "function main taking no parameters and returning no values function body 2 statements"},
+		{nav.Next, "function other"},
+		{nav.Previous, "function main"},
+		{nav.Into, "let x equal one"},
+		{nav.Next, "if x is greater than zero then 1 statement"},
+		{nav.Into, "return"},
+		{nav.Where, "in function main line 6 statement 1 of 1 nesting level 2"},
+		{nav.Next, "end of block"},
+		{nav.Out, "if x is greater than zero"},
+		{nav.Out, "function main"},
+		{nav.Out, "already at top level"},
+		{func() error { return nav.Seek(6) }, "return"},
+		{nav.Where, "in function main line 6 statement 1 of 1 nesting level 2"},
+		{func() error { return nav.Seek(10) }, "function other"},
+		{func() error { return nav.Seek(2) }, "no declaration on line 2"},
+	}
+
+	for _, step := range steps {
+		if err := step.move(); err != nil {
+			t.Errorf("Navigation failed: %+v\n", err)
+			return
+		}
+		speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+		if !hasSubsequence(splitCommands(speech), splitCommands(step.target)) {
+			t.Errorf("Expected %s, heard %s\n", step.target, speech)
+		}
+	}
+}
+
+func TestNavigatorGroups(t *testing.T) {
+	prog := `package main
+
+const (
+	low  = 1
+	high = 2
+)
+
+func main() {
+	if low > high {
+		return
+	} else {
+		println(low)
+	}
+}`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+	goSpeaker.SetLocale(germanLocale)
+
+	if err := goSpeaker.LoadStringErr(prog); err != nil {
+		t.Errorf("Unable to load: %+v\n", err)
+		return
+	}
+	nav, err := goSpeaker.Navigator()
+	if err != nil {
+		t.Errorf("Unable to navigate: %+v\n", err)
+		return
+	}
+
+	steps := []struct {
+		move   func() error
+		target string
+	}{
+		{nav.Into, "Konstante low vom Typ gleich 1"},
+		{nav.Next, "Konstante high"},
+		{nav.Out, "Konstante low vom Typ gleich 1 Konstante high"},
+		{nav.Next, "Funktion main"},
+		{nav.Into, "wenn low größer als high dann 1 Anweisung sonst 1 Anweisung"},
+		{nav.Into, "gib zurück"},
+		{nav.Next, "sonst Blockanfang 1 Anweisung"},
+	}
+
+	for _, step := range steps {
+		if err := step.move(); err != nil {
+			t.Errorf("Navigation failed: %+v\n", err)
+			return
+		}
+		speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+		if !hasSubsequence(splitCommands(speech), splitCommands(step.target)) {
+			t.Errorf("Expected %s, heard %s\n", step.target, speech)
+		}
+	}
+}
+
+func TestPackage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gospeak")
+	if err != nil {
+		t.Errorf("Unable to create temp dir: %+v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package shapes\n\ntype Shape interface{}\n\nfunc helper() {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b.go"), []byte("package shapes\n\nvar Sides, corners int = 4, 4\n\nfunc NewShape() Shape {\n\treturn nil\n}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "b_test.go"), []byte("package shapes\n\nfunc TestShape() {}\n"), 0644)
+
+	goSpeaker := goSpeaker{
+		quiet:        true,
+		startLine:    -1,
+		endLine:      -1,
+		exportedOnly: true,
+	}
+
+	if err := goSpeaker.SpeakGoPackage(dir); err != nil {
+		t.Errorf("Unable to speak package: %+v\n", err)
+		return
+	}
+
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits := splitCommands(speech)
+	for _, target := range []string{
+		"package shapes 2 files a dot go b dot go 1 exported type Shape 1 exported function New Shape",
+		"file a dot go declarations type Shape is empty interface file b dot go",
+		"var Sides of type int equals four function New Shape",
+		"function New Shape",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+	if strings.Contains(speech, "helper") || strings.Contains(speech, "Test Shape") || strings.Contains(speech, "corners") {
+		t.Errorf("Unexported or test declarations were read: %s\n", speech)
+	}
+
+	// A file with syntax errors is still read, and only it has the errors
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package shapes\n\ntype Shape interface{}\n\nfunc helper() {\n\tx :=\n}\n"), 0644)
+	goSpeaker.exportedOnly = false
+	if err := goSpeaker.SpeakGoPackage(dir); err != nil {
+		t.Errorf("Unable to speak package: %+v\n", err)
+		return
+	}
+	speech = stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits = splitCommands(speech)
+	for _, target := range []string{
+		"package shapes 2 files a dot go b dot go",
+		"file a dot go file has 1 syntax error syntax error on line 7, column 1: expected operand, found closing brace",
+		"function helper",
+		"file b dot go declarations vars Sides of type int equals four corners of type int equals four function New Shape",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+}
+
+func TestOutline(t *testing.T) {
+	prog := `package main
+
+import "fmt"
+
+type speaker struct {
+	quiet, verbose bool
+	name           string
+}
+
+func (s *speaker) Say(words string) error {
+	fmt.Println(words)
+	return nil
+}
+
+func Make(quiet bool, name string) (*speaker, error) {
+	return &speaker{quiet: quiet, name: name}, nil
+}
+`
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+		verbosity: VerbosityOutline,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+
+	target := "package main declarations type speaker struct with 3 fields " +
+		"method Say on pointer to speaker taking 1 parameter returning error " +
+		"function Make taking 2 parameters returning 2 values"
+
+	if !reflect.DeepEqual(splitCommands(speech), splitCommands(target)) {
+		t.Errorf("Expected outline %s, heard %s\n", target, speech)
+	}
+
+	goSpeaker.SpeakGoString("package shapes\n\ntype Shape interface {\n\tfmt.Stringer\n\t~int | ~float64\n\tArea() float64\n\tScale(factor float64) Shape\n}\n")
+	speech = stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	target = "package shapes declarations type Shape interface with 2 methods"
+	if !reflect.DeepEqual(splitCommands(speech), splitCommands(target)) {
+		t.Errorf("Expected outline %s, heard %s\n", target, speech)
+	}
+}
+
+func TestTypeChecked(t *testing.T) {
+	prog := `package main
+
+import "os"
+
+type user struct {
+	Name string
+}
+
+func main() {
+	count := len(os.Args)
+	file, _ := os.Open("x")
+	file.Close()
+	u := user{}
+	println(u.Name, count)
+}
+`
+
+	goSpeaker := goSpeaker{
+		quiet:       true,
+		startLine:   -1,
+		endLine:     -1,
+		typeChecked: true,
+	}
+
+	goSpeaker.SpeakGoString(prog)
+
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits := splitCommands(speech)
+	for _, target := range []string{
+		"let count an int equal len of package oh ess dot Args",
+		"let file a pointer to oh ess dot File and none equal package oh ess dot Open",
+		"call method Close on file of type pointer to oh ess dot File",
+		"field Name of u a struct user",
+		"let u a user equal",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+
+	goSpeaker.SpeakGoString("package main\n\nfunc main() {\n\tx := undefined()\n}\n")
+	speech = stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	if !hasSubsequence(splitCommands(speech), splitCommands("let x equal call undefined")) {
+		t.Errorf("Expected a syntax-only fallback, heard %s\n", speech)
+	}
+	if goSpeaker.typesInfo != nil || goSpeaker.typesPkg != nil {
+		t.Errorf("Expected the type information of the earlier file to be cleared\n")
+	}
+
+	goSpeaker.SpeakGoString("package main\n\nfunc main() {\n\tunused := 1\n}\n")
+	speech = stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	if !hasSubsequence(splitCommands(speech), splitCommands("let unused an int equal one")) {
+		t.Errorf("Expected types despite an unused variable, heard %s\n", speech)
+	}
+
+	for spoken, expected := range map[string]string{
+		"you int":         "a you int",
+		"user":            "a user",
+		"uint":            "a uint",
+		"unique ID":       "a unique ID",
+		"uninitialized":   "an uninitialized",
+		"umbrella":        "an umbrella",
+		"hour":            "an hour",
+		"handler":         "a handler",
+		"int":             "an int",
+		"H T T P Server":  "an H T T P Server",
+		"U R L":           "a U R L",
+		"pointer to user": "a pointer to user",
+	} {
+		if withArticle(spoken) != expected {
+			t.Errorf("Expected %s, got %s\n", expected, withArticle(spoken))
+		}
+	}
+}
+
+func TestLexicon(t *testing.T) {
+	home, err := ioutil.TempDir("", "gospeak-home")
+	if err != nil {
+		t.Errorf("Unable to create temp dir: %+v", err)
+		return
+	}
+	defer os.RemoveAll(home)
+	project, err := ioutil.TempDir("", "gospeak-project")
+	if err != nil {
+		t.Errorf("Unable to create temp dir: %+v", err)
+		return
+	}
+	defer os.RemoveAll(project)
+
+	t.Setenv("HOME", home)
+	ioutil.WriteFile(filepath.Join(home, LexiconFilename), []byte(`{"fmt": "format", "ctx": "context"}`), 0644)
+	ioutil.WriteFile(filepath.Join(project, "go.mod"), []byte("module example\n"), 0644)
+	ioutil.WriteFile(filepath.Join(project, LexiconFilename), []byte(`{"FMT": "fumpt"}`), 0644)
+	sub := filepath.Join(project, "sub")
+	os.Mkdir(sub, 0755)
+
+	lexicon, err := LoadDefaultLexicon(sub)
+	if err != nil {
+		t.Errorf("Unable to load lexicon: %+v", err)
+		return
+	}
+	expected := Lexicon{"fmt": "fumpt", "ctx": "context"}
+	if !reflect.DeepEqual(lexicon, expected) {
+		t.Errorf("Expected %v, got %v", expected, lexicon)
+	}
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+		lexicon:   lexicon,
+	}
+	goSpeaker.SpeakGoString("package main\n\nfunc main() {\n\tfmt.Println(ctx, newCtx)\n}\n")
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	target := "fumpt dot Println of context comma new context"
+	if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+		t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+	}
+}
+
+func TestSplitSymbol(t *testing.T) {
+	for _, test := range []struct {
+		symbol string
+		words  []string
+	}{
+		{"x", []string{"x"}},
+		{"Println", []string{"Println"}},
+		{"MakeGoSpeakerDefault", []string{"Make", "Go", "Speaker", "Default"}},
+		{"goSpeaker", []string{"go", "Speaker"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"ServeHTTP", []string{"Serve", "HTTP"}},
+		{"getHTTPResponseCode", []string{"get", "HTTP", "Response", "Code"}},
+		{"XMLHttpRequest", []string{"XML", "Http", "Request"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"URLsByHost", []string{"URLs", "By", "Host"}},
+		{"utf8", []string{"utf", "8"}},
+		{"base64Encoding", []string{"base", "64", "Encoding"}},
+		{"float64", []string{"float", "64"}},
+		{"Int64Slice", []string{"Int", "64", "Slice"}},
+		{"v2", []string{"v", "2"}},
+		{"snake_case_name", []string{"snake", "case", "name"}},
+		{"_private", []string{"private"}},
+		{"MAX_VALUE", []string{"MAX", "VALUE"}},
+		{"_", []string{"_"}},
+		{"fmt.Println", []string{"fmt", ".", "Println"}},
+		{"github.com/wutka/gospeak", []string{"github", ".", "com", "/", "wutka", "/", "gospeak"}},
+		{"naïveÜber", []string{"naïve", "Über"}},
+	} {
+		words := splitSymbol(test.symbol)
+		if !reflect.DeepEqual(words, test.words) {
+			t.Errorf("splitSymbol(%q) = %q, expected %q", test.symbol, words, test.words)
+		}
+	}
+}
+
+func TestSymbolToSpeech(t *testing.T) {
+	gsp := &goSpeaker{}
+	for _, test := range []struct {
+		symbol string
+		speech string
+	}{
+		{"MakeGoSpeakerDefault", "Make Go Speaker Default"},
+		{"HTTPServer", "H T T P Server"},
+		{"userID", "user I D"},
+		{"userIDs", "user I Ds"},
+		{"parseURL", "parse U R L"},
+		{"id", "i d"},
+		{"APIKey", "A P I Key"},
+		{"utf8", "you tee f 8"},
+		{"base64", "base 64"},
+		{"decodeJSON", "decode jay son"},
+		{"fmt", "fumt"},
+		{"Printf", "print f"},
+		{"os.Args", "oh ess dot Args"},
+		{"_", "none"},
+		{"max_retry_count", "max retry count"},
+	} {
+		speech := gsp.symbolToSpeech(test.symbol)
+		if speech != test.speech {
+			t.Errorf("symbolToSpeech(%q) = %q, expected %q", test.symbol, speech, test.speech)
+		}
+	}
+
+	gsp.lexicon = Lexicon{"http": "hypertext", "userid": "user identifier"}
+	for symbol, speech := range map[string]string{
+		"HTTPServer": "hypertext Server",
+		"userID":     "user identifier",
+	} {
+		if heard := gsp.symbolToSpeech(symbol); heard != speech {
+			t.Errorf("symbolToSpeech(%q) with lexicon = %q, expected %q", symbol, heard, speech)
+		}
+	}
+}
+
+func TestLiterals(t *testing.T) {
+	gsp := &goSpeaker{}
+	for _, test := range []struct {
+		literal string
+		speech  string
+	}{
+		{"0", "zero"},
+		{"42", "forty-two"},
+		{"1_000_000", "one million"},
+		{"1234567", "one million two hundred thirty-four thousand five hundred sixty-seven"},
+		{"100000000000000000000", "100000000000000000000"},
+		{"0xFF", "hex F F"},
+		{"0x_dead_beef", "hex D E A D B E E F"},
+		{"0o17", "octal 1 7"},
+		{"017", "octal 1 7"},
+		{"0b1010", "binary 1 0 1 0"},
+		{"3.14", "three point one four"},
+		{"2.", "two point zero"},
+		{".5", "point five"},
+		{"1e6", "one times ten to the six"},
+		{"6.02e+23", "six point zero two times ten to the twenty-three"},
+		{"1.5E-3", "one point five times ten to the minus three"},
+		{"0x1p-2", "hex 1 times two to the minus two"},
+		{"2i", "imaginary two"},
+		{"1.5i", "imaginary one point five"},
+		{"0123i", "imaginary one hundred twenty-three"},
+		{"'a'", "character a"},
+		{"'\\n'", "newline character"},
+		{"'\\''", "single quote character"},
+		{"'\\x41'", "hex 4 1 character"},
+		{"'\\u00e9'", "unicode 0 0 E 9 character"},
+		{"' '", "space character"},
+		{"'.'", "dot character"},
+		{"\"tab\\there\\n\"", "tab tab here newline"},
+		{"\"\\\"quoted\\\"\"", "double quote quoted double quote"},
+		{"\"\\101\"", "octal 1 0 1"},
+		{"`json:\"name\"`", "json:\"name\""},
+		{"`\nline one\nline two\nline three`", "raw string spanning 4 lines"},
+		{"``", "empty raw string"},
+	} {
+		expr, err := parser.ParseExpr(test.literal)
+		if err != nil {
+			t.Errorf("Unable to parse %s: %+v", test.literal, err)
+			continue
+		}
+		gsp.events = nil
+		gsp.speakLiteral(expr.(*ast.BasicLit))
+		// Escapes are phrases of their own, which are spaced apart
+		speech := strings.Join(strings.Fields(stripMarkers(stripNewlines(stripPause(gsp.GetSpeechString())))), " ")
+		if speech != test.speech {
+			t.Errorf("Literal %s spoken as %q, expected %q", test.literal, speech, test.speech)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	oldSource := `package main
+
+import "fmt"
+
+func helper() int {
+	return 1
+}
+
+func main() {
+	x := helper()
+	fmt.Println(x)
+	fmt.Println("done")
+}
+`
+	newSource := `package main
+
+import "fmt"
+
+func main() {
+	x := 2
+	fmt.Println(x)
+	if x > 1 {
+		fmt.Println("big")
+	}
+}
+`
+	diffText := `diff --git a/m.go b/m.go
+index 3063fa9..6cfb9ff 100644
+--- a/m.go
++++ b/m.go
+@@ -5,4 +4,0 @@ import "fmt"
+-func helper() int {
+-	return 1
+-}
+-
+@@ -10 +6 @@ func main() {
+-	x := helper()
++	x := 2
+@@ -12 +8,3 @@ func main() {
+-	fmt.Println("done")
++	if x > 1 {
++		fmt.Println("big")
++	}
+`
+	diffs, err := ParseDiff(strings.NewReader(diffText))
+	if err != nil {
+		t.Fatalf("Unable to parse diff: %+v", err)
+	}
+	expected := []FileDiff{{
+		OldFilename: "m.go",
+		Filename:    "m.go",
+		Changes: []Change{
+			{Kind: ChangeRemoved, Start: 5, End: 4, OldStart: 5, OldEnd: 8},
+			{Kind: ChangeModified, Start: 6, End: 6, OldStart: 10, OldEnd: 10},
+			{Kind: ChangeModified, Start: 8, End: 10, OldStart: 12, OldEnd: 12},
+		},
+	}}
+	if !reflect.DeepEqual(diffs, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, diffs)
+	}
+
+	dir, err := ioutil.TempDir("", "gospeak")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %+v", err)
+	}
+	defer os.RemoveAll(dir)
+	diffs[0].Filename = filepath.Join(dir, "m.go")
+	ioutil.WriteFile(diffs[0].Filename, []byte(newSource), 0644)
+
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+	if err := goSpeaker.SpeakDiff(diffs[0], oldSource); err != nil {
+		t.Fatalf("Unable to speak diff: %+v", err)
+	}
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits := splitCommands(speech)
+	for _, target := range []string{
+		"removed 4 lines before line 5 which held function helper taking no parameters returning int",
+		"modified line 6 in function main let x equal two replacing 1 statement from function main",
+		"modified lines 8 to 10 in function main if x is greater than one",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+	if goSpeaker.FunctionAt(7) != "main" || goSpeaker.FunctionAt(3) != "" {
+		t.Errorf("Expected line 7 in main and line 3 outside any function")
+	}
+	if goSpeaker.startLine != -1 || goSpeaker.endLine != -1 {
+		t.Errorf("SpeakDiff left the range set to %d-%d", goSpeaker.startLine, goSpeaker.endLine)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	oldSource := `package main
+
+const limit = 3
+
+type Point struct {
+	X, Y int
+}
+
+func helper() int {
+	return 1
+}
+
+func (p *Point) Sum() int {
+	return p.X + p.Y
+}
+`
+	newSource := `package main
+
+const limit = 3
+
+type Point struct {
+	X, Y int
+}
+
+func (p *Point) Sum() int {
+	return p.X - p.Y
+}
+
+func main() {
+	x := )
+}
+`
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+	}
+	if err := goSpeaker.LoadStringErr(newSource); err != nil {
+		t.Fatalf("Unable to load: %+v", err)
+	}
+	if err := goSpeaker.SpeakChanges(oldSource); err != nil {
+		t.Fatalf("Unable to speak changes: %+v", err)
+	}
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	splits := splitCommands(speech)
+	for _, target := range []string{
+		"changed method Sum function Sum with 1 receiver p as pointer to Point",
+		"return p dot X minus p dot Y end function Sum",
+		"added function main file has 2 syntax errors",
+		"let x equal syntax error in expression on line 14 end function main",
+		"removed function helper",
+	} {
+		if !hasSubsequence(splits, splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+	for _, unchanged := range []string{"limit", "struct"} {
+		if strings.Contains(speech, unchanged) {
+			t.Errorf("Expected only the changes, got %s", speech)
+		}
+	}
+
+	if err := goSpeaker.SpeakChanges(newSource); err != nil {
+		t.Fatalf("Unable to speak changes: %+v", err)
+	}
+	if speech = strings.TrimSpace(stripNewlines(stripPause(goSpeaker.GetSpeechString()))); speech != "no changes" {
+		t.Errorf("Expected no changes, got %s", speech)
+	}
+}
+
+// toneTestBackend synthesizes every phrase as 100ms of silence.
+type toneTestBackend struct{}
+
+func (toneTestBackend) Speak(script string, audioOutputFile string) error {
+	return nil
+}
+
+func (toneTestBackend) SynthesizePhrase(ctx context.Context, phrase string, wavFile string) error {
+	format := wavFormat{channels: 1, sampleRate: 8000, bitsPerSample: 16}
+	return writeWAV(wavFile, format, silence(format, 100))
+}
+
+// blockingBackend speaks, or synthesizes a phrase, until it is stopped.
+type blockingBackend struct {
+	started chan struct{}
+}
+
+func (b blockingBackend) Speak(script string, audioOutputFile string) error {
+	return nil
+}
+
+func (b blockingBackend) SpeakContext(ctx context.Context, script string, audioOutputFile string) error {
+	b.started <- struct{}{}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b blockingBackend) SynthesizePhrase(ctx context.Context, phrase string, wavFile string) error {
+	return b.SpeakContext(ctx, phrase, "")
+}
+
+func TestStop(t *testing.T) {
+	backend := blockingBackend{started: make(chan struct{}, 1)}
+	speaker := MakeGoSpeaker(false, false, false, VerbosityFull, "", backend)
+	if err := speaker.LoadStringErr("package main\n\nfunc main() {\n}\n"); err != nil {
+		t.Fatalf("Unable to load: %+v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := speaker.SpeakAllContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline to stop the speech, got %+v", err)
+	}
+	<-backend.started
+
+	result := make(chan error)
+	go func() {
+		result <- speaker.SpeakRangeContext(context.Background(), 3, 4)
+	}()
+	<-backend.started
+	playing := speaker.(*goSpeaker).currentPlayback()
+	paused := func() bool {
+		playing.mutex.Lock()
+		defer playing.mutex.Unlock()
+		return playing.resumed != nil
+	}
+	if err := speaker.Pause(); err != nil || !paused() {
+		t.Errorf("Unable to pause: %+v", err)
+	}
+	if err := speaker.Resume(); err != nil || paused() {
+		t.Errorf("Unable to resume: %+v", err)
+	}
+	// Paused speech can be stopped too
+	if err := speaker.Pause(); err != nil || !paused() {
+		t.Errorf("Unable to pause again: %+v", err)
+	}
+	speaker.Stop()
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("Expected Stop to cancel the speech, got %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop didn't stop the speech")
+	}
+
+	// Stop ends a phrase while it is being synthesized, too
+	speaker = MakeGoSpeaker(false, false, false, VerbosityFull, filepath.Join(t.TempDir(), "speech.wav"), backend)
+	speaker.SetCaptioned(true)
+	if err := speaker.LoadStringErr("package main\n\nfunc main() {\n}\n"); err != nil {
+		t.Fatalf("Unable to load: %+v", err)
+	}
+	go func() {
+		result <- speaker.SpeakAllContext(context.Background())
+	}()
+	<-backend.started
+	speaker.Stop()
+	select {
+	case err := <-result:
+		if err != context.Canceled {
+			t.Errorf("Expected Stop to cancel the synthesis, got %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop didn't stop the synthesis")
+	}
+}
+
+func TestPauseProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows can only pause between phrases")
+	}
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep is not available")
+	}
+	p := &playback{}
+	ctx := context.WithValue(context.Background(), playbackKey{}, p)
+	running := func() *os.Process {
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+		return p.process
+	}
+
+	// A command paused before it starts waits to start
+	if err := p.pause(); err != nil {
+		t.Errorf("Unable to pause: %+v", err)
+		return
+	}
+	result := make(chan error, 1)
+	go func() {
+		result <- runSpeechCommand(ctx, exec.CommandContext(ctx, "sleep", "0.3"))
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if running() != nil {
+		t.Errorf("Expected the command to wait to start while paused")
+		return
+	}
+	if err := p.resume(); err != nil {
+		t.Errorf("Unable to resume: %+v", err)
+		return
+	}
+	for start := time.Now(); running() == nil; time.Sleep(time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Errorf("Expected the command to start once resumed")
+			return
+		}
+	}
+
+	// A command that has started is stopped where it is
+	if err := p.pause(); err != nil {
+		t.Errorf("Unable to pause the command: %+v", err)
+		return
+	}
+	select {
+	case err := <-result:
+		t.Errorf("Expected the paused command to be held, but it finished with %+v", err)
+		return
+	case <-time.After(600 * time.Millisecond):
+	}
+	if err := p.resume(); err != nil {
+		t.Errorf("Unable to resume the command: %+v", err)
+		return
+	}
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("Expected the resumed command to complete, got %+v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Errorf("The resumed command didn't complete")
+	}
+}
+
+func TestEarcons(t *testing.T) {
+	prog := `package main
+
+func main() {
+	x := 1
+	if x > 0 {
+		return
+	} else {
+		x = 2
+	}
+}
+`
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+		earcons:   DefaultEarcons(),
+	}
+	goSpeaker.SpeakGoString(prog)
+	speech := stripNewlines(strings.Replace(goSpeaker.GetSpeechString(), "{pause}", " ", -1))
+	for _, target := range []string{
+		"function main taking no parameters and returning no values {earcon 523.25 90}",
+		"let x equal one {earcon 1174.66 20} if x is greater than zero {earcon 739.98 60} return",
+		"return {earcon 622.26 60} let x equal two {earcon 554.36 60} {earcon 392.00 90}",
+	} {
+		if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+			t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+		}
+	}
+	for _, words := range []string{"function body", "end function", "then", "else", "end if"} {
+		if strings.Contains(speech, words) {
+			t.Errorf("Expected an earcon instead of %s: %s", words, speech)
+		}
+	}
+	if markup := sayMarkup(goSpeaker.GetSpeechString()); !strings.Contains(markup, "values[[slnc 200]]\n[[slnc 90]]") {
+		t.Errorf("Expected earcons to become silences for say: %s", markup)
+	}
+	var buf bytes.Buffer
+	if err := WriteSpeechJSON(&buf, goSpeaker.events); err != nil || strings.Contains(buf.String(), "earcon") {
+		t.Errorf("Expected no earcons in the JSON phrases: %s (%+v)", buf.String(), err)
+	}
+
+	wav, err := ioutil.TempFile("", "gospeak*.wav")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %+v", err)
+	}
+	wav.Close()
+	defer os.Remove(wav.Name())
+
+	goSpeaker.quiet = false
+	goSpeaker.backend = toneTestBackend{}
+	goSpeaker.audioOutputFile = wav.Name()
+	if err := goSpeaker.speakBuffer(); err != nil {
+		t.Fatalf("Unable to mix earcons: %+v", err)
+	}
+	var expected time.Duration
+	for _, e := range goSpeaker.events {
+		if earcon, ok := parseEarcon(e.Phrase); ok {
+			expected += time.Duration(earcon.Duration)*time.Millisecond + earconGap
+		} else {
+			expected += 100*time.Millisecond + phrasePause
+		}
+	}
+	if d, err := wavDuration(wav.Name()); err != nil || d != expected {
+		t.Errorf("Expected %v of mixed audio, got %v (%+v)", expected, d, err)
+	}
+	captions := goSpeaker.Captions()
+	last := captions[len(captions)-1]
+	if end := last.End + phrasePause + 60*time.Millisecond + 90*time.Millisecond + 2*earconGap; end != expected {
+		t.Errorf("Captions end at %v, audio at %v", end, expected)
+	}
+}
+
+func TestLocales(t *testing.T) {
+	for name, locale := range locales {
+		for key := range englishLocale {
+			if _, ok := locale[key]; !ok {
+				t.Errorf("Locale %s has no message %s", name, key)
+			}
+		}
+		goSpeaker := goSpeaker{locale: locale, logger: nopLogger{}}
+		for key, text := range locale {
+			if _, ok := englishLocale[key]; !ok {
+				t.Errorf("Locale %s has unknown message %s", name, key)
+			}
+			if _, err := template.New(key).Funcs(goSpeaker.templateFuncs()).Parse(text); err != nil {
+				t.Errorf("Locale %s message %s doesn't parse: %+v", name, key, err)
+			}
+		}
+	}
+
+	prog := `
+package main
+
+func main() {
+	for i := 0; i < 3; i++ {
+		if i > 1 {
+			return
+		}
+	}
+}
+`
+	tests := []struct {
+		lang    string
+		targets []string
+	}{
+		{"es", []string{
+			"función main que recibe cero parámetros y devuelve cero valores cuerpo de la función",
+			"si i es mayor que 1 entonces devolver fin del si fin del bucle para fin de la función main",
+		}},
+		{"de", []string{
+			"Funktion main nimmt keine Parameter und liefert keine Werte Funktionsrumpf",
+			"wenn i größer als 1 dann gib zurück Ende wenn Ende der Für-Schleife Ende der Funktion main",
+		}},
+	}
+	for _, test := range tests {
+		locale, err := BuiltinLocale(test.lang)
+		if err != nil {
+			t.Fatalf("%+v", err)
+		}
+		goSpeaker := goSpeaker{
+			quiet:     true,
+			startLine: -1,
+			endLine:   -1,
+			locale:    locale,
+		}
+		goSpeaker.SpeakGoString(prog)
+		speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+		for _, target := range test.targets {
+			if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+				t.Errorf("Could not find subsequence in %s: %s\n%s\n", test.lang, target, speech)
+			}
+		}
+	}
+
+	if _, err := BuiltinLocale("xx"); err == nil {
+		t.Errorf("Expected an error for an unknown language")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	for name, profile := range profiles {
+		goSpeaker := goSpeaker{profile: profile, logger: nopLogger{}}
+		for key, text := range profile {
+			if _, ok := englishLocale[key]; !ok && !strings.HasSuffix(key, " node") {
+				t.Errorf("Profile %s has unknown message %s", name, key)
+			}
+			if _, err := template.New(key).Funcs(goSpeaker.templateFuncs()).Parse(text); err != nil {
+				t.Errorf("Profile %s message %s doesn't parse: %+v", name, key, err)
+			}
+		}
+	}
+
+	prog := `
+package main
+
+func main() {
+	var p *int
+	x := 1
+	x += 2
+	x++
+	f(x, p)
+}
+`
+	tests := []struct {
+		name    string
+		profile Profile
+		targets []string
+	}{
+		{"terse", terseProfile, []string{
+			"func main var p star int x is one x plus equals two",
+			"f of x comma p end main",
+		}},
+		{"verbose", verboseProfile, []string{
+			"declare function main",
+			"define x as one set x to itself plus two add one to x",
+			"f with arguments x comma p end of function main",
+		}},
+		{"custom", Profile{
+			"StarExpr type":   "star",
+			"AssignStmt node": `assign {{join .Rhs "and"}} to {{join .Lhs "and"}}`,
+			"CallExpr node":   `call {{.Fun}} with {{len .Args}} arguments`,
+		}, []string{
+			"var p of type star int assign one to x",
+			"increment x call f with 2 arguments",
+		}},
+	}
+	for _, test := range tests {
+		goSpeaker := goSpeaker{
+			quiet:     true,
+			startLine: -1,
+			endLine:   -1,
+			profile:   test.profile,
+		}
+		goSpeaker.SpeakGoString(prog)
+		speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+		for _, target := range test.targets {
+			if !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+				t.Errorf("Could not find subsequence in %s: %s\n%s\n", test.name, target, speech)
+			}
+		}
+	}
+
+	if _, err := BuiltinProfile("chatty"); err == nil {
+		t.Errorf("Expected an error for an unknown profile")
+	}
+
+	// The built-in profiles are English, so German is read as it is
+	goSpeaker := goSpeaker{
+		quiet:     true,
+		startLine: -1,
+		endLine:   -1,
+		locale:    germanLocale,
+		profile:   terseProfile,
+	}
+	if terseProfile.AppliesTo(germanLocale) || !terseProfile.AppliesTo(nil) {
+		t.Errorf("Expected the terse profile to apply to English only")
+	}
+	goSpeaker.SpeakGoString(prog)
+	speech := stripNewlines(stripPause(goSpeaker.GetSpeechString()))
+	if target := "Funktion main"; !hasSubsequence(splitCommands(speech), splitCommands(target)) {
+		t.Errorf("Could not find subsequence: %s\n%s\n", target, speech)
+	}
+}
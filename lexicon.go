@@ -0,0 +1,103 @@
+package gospeak
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LexiconFilename is the name of the lexicon file looked for in the
+// project root and in the user's home directory.
+const LexiconFilename = ".gospeak-lexicon.json"
+
+// Lexicon maps identifiers, package names and acronyms to the way they
+// should be spoken. Keys are matched without regard to case, first against
+// a whole identifier and then against each word split from it. A lexicon
+// file is a JSON object such as {"fmt": "fumt", "gsp": "g s p"}.
+type Lexicon map[string]string
+
+func (gsp *goSpeaker) SetLexicon(lexicon Lexicon) {
+	gsp.lexicon = lexicon
+}
+
+func LoadLexicon(filename string) (Lexicon, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	entries := map[string]string{}
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse lexicon %s: %+v", filename, err)
+	}
+	lexicon := Lexicon{}
+	for word, speech := range entries {
+		lexicon[strings.ToLower(word)] = speech
+	}
+	return lexicon, nil
+}
+
+// BuiltinPronunciations returns the spoken forms gospeak gives common
+// package names, abbreviations and symbols when no lexicon entry applies.
+func BuiltinPronunciations() Lexicon {
+	return Lexicon{}.Merge(symbolTranslations)
+}
+
+// Merge returns a lexicon holding the entries of both lexicons, with the
+// entries of other taking precedence.
+func (lex Lexicon) Merge(other Lexicon) Lexicon {
+	merged := Lexicon{}
+	for word, speech := range lex {
+		merged[word] = speech
+	}
+	for word, speech := range other {
+		merged[word] = speech
+	}
+	return merged
+}
+
+// LoadDefaultLexicon loads the user's lexicon from their home directory and
+// then the project lexicon from the root of the project containing dir, so
+// that project entries override user entries. Missing files are skipped.
+func LoadDefaultLexicon(dir string) (Lexicon, error) {
+	lexicon := Lexicon{}
+	candidates := []string{}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, LexiconFilename))
+	}
+	candidates = append(candidates, filepath.Join(projectRoot(dir), LexiconFilename))
+
+	for _, filename := range candidates {
+		lex, err := LoadLexicon(filename)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		lexicon = lexicon.Merge(lex)
+	}
+	return lexicon, nil
+}
+
+// projectRoot walks up from dir to the nearest directory with a go.mod or
+// .git entry, falling back to dir itself.
+func projectRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		for _, marker := range []string{"go.mod", ".git"} {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		if filepath.Dir(d) == d {
+			return abs
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package gospeak
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var smallNumberWords = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen",
+	"seventeen", "eighteen", "nineteen",
+}
+
+var tensWords = []string{
+	"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety",
+}
+
+var scaleWords = []string{
+	"", "thousand", "million", "billion", "trillion", "quadrillion", "quintillion",
+}
+
+// namedEscapes are the escapes that are spoken by name, such as \n for
+// newline.
+const namedEscapes = "abfnrtv\\'\""
+
+func (gsp *goSpeaker) speakLiteral(lit *ast.BasicLit) {
+	switch lit.Kind {
+	case token.INT:
+		gsp.speak(gsp.intSpeech(lit.Value))
+	case token.FLOAT:
+		gsp.speak(gsp.floatSpeech(lit.Value))
+	case token.IMAG:
+		gsp.speak(gsp.imagSpeech(lit.Value))
+	case token.CHAR:
+		gsp.speak(gsp.runeSpeech(lit.Value))
+	default:
+		gsp.speakString(lit.Value)
+	}
+}
+
+// numberWords spells out a number in English, so 1000000 is read as one
+// million. Other locales leave numbers as digits for the voice to read.
+func numberWords(n uint64) string {
+	if n < 20 {
+		return smallNumberWords[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return tensWords[n/10]
+		}
+		return tensWords[n/10] + "-" + smallNumberWords[n%10]
+	}
+	if n < 1000 {
+		words := smallNumberWords[n/100] + " hundred"
+		if n%100 != 0 {
+			words += " " + numberWords(n%100)
+		}
+		return words
+	}
+
+	groups := []string{}
+	for scale := 0; n > 0; scale++ {
+		if group := n % 1000; group != 0 {
+			words := numberWords(group)
+			if scaleWords[scale] != "" {
+				words += " " + scaleWords[scale]
+			}
+			groups = append([]string{words}, groups...)
+		}
+		n /= 1000
+	}
+	return strings.Join(groups, " ")
+}
+
+// spellDigits reads digits one at a time, dropping underscore separators.
+func spellDigits(digits string) string {
+	spelled := []string{}
+	for _, ch := range strings.ToUpper(strings.Replace(digits, "_", "", -1)) {
+		spelled = append(spelled, string(ch))
+	}
+	return strings.Join(spelled, " ")
+}
+
+// decimalWords reads a run of decimal digits as a number, falling back to
+// the digits themselves when the number is too large to spell out.
+func (gsp *goSpeaker) decimalWords(digits string) string {
+	digits = strings.Replace(digits, "_", "", -1)
+	n, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return digits
+	}
+	return gsp.phrase("Number", "Value", n)
+}
+
+func (gsp *goSpeaker) intSpeech(lit string) string {
+	lower := strings.ToLower(lit)
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		return gsp.phrase("BasicLit hex", "Digits", spellDigits(lit[2:]))
+	case strings.HasPrefix(lower, "0b"):
+		return gsp.phrase("BasicLit binary", "Digits", spellDigits(lit[2:]))
+	case strings.HasPrefix(lower, "0o"):
+		return gsp.phrase("BasicLit octal", "Digits", spellDigits(lit[2:]))
+	case len(lit) > 1 && lit[0] == '0':
+		return gsp.phrase("BasicLit octal", "Digits", spellDigits(strings.TrimPrefix(lit[1:], "_")))
+	}
+	return gsp.decimalWords(lit)
+}
+
+func (gsp *goSpeaker) floatSpeech(lit string) string {
+	lower := strings.ToLower(lit)
+	if strings.HasPrefix(lower, "0x") {
+		mantissa, exponent := lower[2:], ""
+		if i := strings.Index(mantissa, "p"); i >= 0 {
+			mantissa, exponent = mantissa[:i], mantissa[i+1:]
+		}
+		speech := gsp.phrase("BasicLit hex", "Digits",
+			strings.Replace(spellDigits(mantissa), ".", gsp.phrase("BasicLit point"), -1))
+		if exponent != "" {
+			speech += " " + gsp.phrase("BasicLit binary exponent", "Exponent", gsp.exponentSpeech(exponent))
+		}
+		return speech
+	}
+
+	mantissa, exponent := lower, ""
+	if i := strings.Index(mantissa, "e"); i >= 0 {
+		mantissa, exponent = mantissa[:i], mantissa[i+1:]
+	}
+	words := []string{}
+	whole, frac := mantissa, ""
+	hasPoint := false
+	if i := strings.Index(mantissa, "."); i >= 0 {
+		whole, frac, hasPoint = mantissa[:i], mantissa[i+1:], true
+	}
+	if whole != "" {
+		words = append(words, gsp.decimalWords(whole))
+	}
+	if hasPoint {
+		words = append(words, gsp.phrase("BasicLit point"))
+		if frac == "" {
+			frac = "0"
+		}
+		for _, ch := range strings.Replace(frac, "_", "", -1) {
+			words = append(words, gsp.phrase("Number", "Value", uint64(ch-'0')))
+		}
+	}
+	if exponent != "" {
+		words = append(words, gsp.phrase("BasicLit exponent", "Exponent", gsp.exponentSpeech(exponent)))
+	}
+	return strings.Join(words, " ")
+}
+
+func (gsp *goSpeaker) exponentSpeech(exponent string) string {
+	if strings.HasPrefix(exponent, "-") {
+		return gsp.phrase("BasicLit negative", "Number", gsp.decimalWords(exponent[1:]))
+	}
+	return gsp.decimalWords(strings.TrimPrefix(exponent, "+"))
+}
+
+func (gsp *goSpeaker) imagSpeech(lit string) string {
+	number := strings.TrimSuffix(lit, "i")
+	lower := strings.ToLower(number)
+	var speech string
+	switch {
+	case strings.HasPrefix(lower, "0x") && strings.Contains(lower, "p"):
+		speech = gsp.floatSpeech(number)
+	case strings.HasPrefix(lower, "0x"), strings.HasPrefix(lower, "0b"), strings.HasPrefix(lower, "0o"):
+		speech = gsp.intSpeech(number)
+	case strings.ContainsAny(lower, ".e"):
+		speech = gsp.floatSpeech(number)
+	default:
+		// For backward compatibility, 0123i is decimal rather than octal.
+		speech = gsp.decimalWords(number)
+	}
+	return gsp.phrase("BasicLit imaginary", "Number", speech)
+}
+
+// escapeSpeech names the escape sequence at the start of s, which begins
+// with a backslash, and returns how many bytes it used.
+func (gsp *goSpeaker) escapeSpeech(s string) (string, int) {
+	if len(s) < 2 {
+		return gsp.phrase(`Escape \`), len(s)
+	}
+	if strings.IndexByte(namedEscapes, s[1]) >= 0 {
+		return gsp.phrase("Escape " + s[1:2]), 2
+	}
+	digits := func(message string, start, count int) (string, int) {
+		end := start + count
+		if end > len(s) {
+			end = len(s)
+		}
+		return gsp.phrase(message, "Digits", spellDigits(s[start:end])), end
+	}
+	switch s[1] {
+	case 'x':
+		return digits("Escape hex", 2, 2)
+	case 'u':
+		return digits("Escape unicode", 2, 4)
+	case 'U':
+		return digits("Escape unicode", 2, 8)
+	}
+	if s[1] >= '0' && s[1] <= '7' {
+		return digits("Escape octal", 1, 3)
+	}
+	return gsp.phrase(`Escape \`), 1
+}
+
+// speakInterpreted speaks the body of an interpreted string: its text as
+// literal phrases, broken at each escape sequence, which is named in a
+// phrase of its own.
+func (gsp *goSpeaker) speakInterpreted(s string) {
+	var sb strings.Builder
+	flush := func() {
+		if strings.TrimSpace(sb.String()) != "" {
+			event := gsp.makeEvent(sb.String())
+			event.literal = true
+			gsp.addEvent(event)
+		}
+		sb.Reset()
+	}
+	for i := 0; i < len(s); {
+		if s[i] != '\\' {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		flush()
+		name, n := gsp.escapeSpeech(s[i:])
+		event := gsp.makeEvent(name)
+		event.escape = true
+		gsp.addEvent(event)
+		i += n
+	}
+	flush()
+}
+
+func (gsp *goSpeaker) runeSpeech(lit string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(lit, "'"), "'")
+	if strings.HasPrefix(body, "\\") {
+		name, _ := gsp.escapeSpeech(body)
+		return gsp.phrase("BasicLit named character", "Name", name)
+	}
+	r := []rune(body)
+	if len(r) != 1 {
+		return gsp.phrase("BasicLit character", "Character", body)
+	}
+	if r[0] == ' ' {
+		return gsp.phrase("BasicLit space")
+	}
+	if !unicode.IsLetter(r[0]) && !unicode.IsDigit(r[0]) {
+		if name, ok := gsp.lookupSymbol(body); ok {
+			return gsp.phrase("BasicLit named character", "Name", name)
+		}
+	}
+	return gsp.phrase("BasicLit character", "Character", body)
+}
+
+func (gsp *goSpeaker) speakRawString(s string) {
+	s = s[1 : len(s)-1]
+	lines := strings.Count(s, "\n") + 1
+	if len(s) == 0 {
+		gsp.speakPhrase("BasicLit empty raw")
+	} else if lines > 1 {
+		gsp.speakPhrase("BasicLit raw lines", "Count", lines)
+	} else {
+		event := gsp.makeEvent(s)
+		event.literal = true
+		gsp.addEvent(event)
+	}
+}
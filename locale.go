@@ -0,0 +1,450 @@
+package gospeak
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Locale holds the words gospeak speaks in one language. Each message is a
+// text/template, so that a language can put the words around a name or a
+// count in its own order. Messages are keyed by node kind, as in
+// SpeechEvent.Kind, followed by the part of the node they speak, such as
+// "FuncDecl" for the start of a function and "FuncDecl end" for its end. An
+// empty message says nothing, which lets a language move a word from one
+// side of a child node to the other. Messages missing from a locale are
+// spoken in English. A Profile, if there is one, is looked at before the
+// locale.
+//
+// The "Language" entry isn't spoken: it is the language tag of the locale,
+// such as de-DE, which SSML is marked with and which picks a voice when
+// none is given.
+//
+// Besides the fields passed to each message, templates can use:
+//
+//	count N "noun"   N of a noun, as in "no parameters" or "2 values"
+//	plural N A B     A when N is one, otherwise B
+//	words N          N spelled out in English, for English locales only
+//	article S        S with "a" or "an" before it
+//	join L "word"    the speech in list L with a word between each
+type Locale map[string]string
+
+var locales = map[string]Locale{
+	"en": englishLocale,
+	"es": spanishLocale,
+	"de": germanLocale,
+}
+
+// BuiltinLocale returns one of the locales that ship with gospeak: en, es or
+// de.
+func BuiltinLocale(name string) (Locale, error) {
+	locale, ok := locales[strings.ToLower(name)]
+	if !ok {
+		names := []string{}
+		for name := range locales {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown language %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return locale, nil
+}
+
+// LoadLocale reads a locale from a JSON file mapping message keys to
+// templates, such as {"FuncDecl": "func {{.Name}}"}.
+func LoadLocale(filename string) (Locale, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	locale := Locale{}
+	err = json.Unmarshal(data, &locale)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse locale %s: %+v", filename, err)
+	}
+	return locale, nil
+}
+
+// Language returns the language tag of a locale, or en-US if it has none.
+func (locale Locale) Language() string {
+	if tag := locale["Language"]; tag != "" {
+		return tag
+	}
+	return "en-US"
+}
+
+// SetLocale speaks code in the words of another language. Nil goes back to
+// English.
+func (gsp *goSpeaker) SetLocale(locale Locale) {
+	gsp.locale = locale
+	gsp.templates = nil
+}
+
+// phrase renders a message from the locale. Its fields are given as name,
+// value pairs.
+func (gsp *goSpeaker) phrase(key string, fields ...interface{}) string {
+	data := map[string]interface{}{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		data[fields[i].(string)] = fields[i+1]
+	}
+	var sb strings.Builder
+	err := gsp.messageTemplate(key).Execute(&sb, data)
+	if err != nil {
+		gsp.logf("Unable to render message %s: %+v\n", key, err)
+		return key
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// speakPhrase speaks a message, unless the locale leaves it empty.
+func (gsp *goSpeaker) speakPhrase(key string, fields ...interface{}) {
+	if speech := gsp.phrase(key, fields...); speech != "" {
+		gsp.speak(speech)
+	}
+}
+
+func (gsp *goSpeaker) messageTemplate(key string) *template.Template {
+	if tmpl, ok := gsp.templates[key]; ok {
+		return tmpl
+	}
+	if gsp.templates == nil {
+		gsp.templates = map[string]*template.Template{}
+	}
+
+	var tmpl *template.Template
+	for _, messages := range gsp.catalogs() {
+		text, ok := messages[key]
+		if !ok {
+			continue
+		}
+		var err error
+		tmpl, err = template.New(key).Funcs(gsp.templateFuncs()).Parse(text)
+		if err == nil {
+			break
+		}
+		gsp.logf("Unable to parse message %s: %+v\n", key, err)
+	}
+	if tmpl == nil {
+		gsp.logf("No message for %s\n", key)
+		tmpl = template.Must(template.New(key).Parse(""))
+	}
+	gsp.templates[key] = tmpl
+	return tmpl
+}
+
+func (gsp *goSpeaker) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"count": gsp.countSpeech,
+		"plural": func(count int, one string, other string) string {
+			if count == 1 {
+				return one
+			}
+			return other
+		},
+		"words":   numberWords,
+		"article": withArticle,
+		"join": func(list []string, word string) string {
+			return strings.Join(list, " "+word+" ")
+		},
+	}
+}
+
+// countSpeech says how many of a noun there are. The noun is the name of a
+// "Noun" message.
+func (gsp *goSpeaker) countSpeech(count int, noun string) string {
+	return gsp.phrase("Count", "Count", count, "Noun", gsp.phrase("Noun "+noun, "Count", count))
+}
+
+var englishLocale = Locale{
+	"Language":                  `en-US`,
+	"Count":                     `{{if eq .Count 0}}no{{else}}{{.Count}}{{end}} {{.Noun}}`,
+	"Noun parameter":            `{{plural .Count "parameter" "parameters"}}`,
+	"Noun type parameter":       `{{plural .Count "type parameter" "type parameters"}}`,
+	"Noun value":                `{{plural .Count "value" "values"}}`,
+	"Noun receiver":             `{{plural .Count "receiver" "receivers"}}`,
+	"Noun field":                `{{plural .Count "field" "fields"}}`,
+	"Noun method":               `{{plural .Count "method" "methods"}}`,
+	"Noun statement":            `{{plural .Count "statement" "statements"}}`,
+	"Noun line":                 `{{plural .Count "line" "lines"}}`,
+	"Noun file":                 `{{plural .Count "file" "files"}}`,
+	"Noun exported type":        `{{plural .Count "exported type" "exported types"}}`,
+	"Noun exported function":    `{{plural .Count "exported function" "exported functions"}}`,
+	"Noun syntax error":         `{{plural .Count "syntax error" "syntax errors"}}`,
+	"Noun declaration":          `{{plural .Count "declaration" "declarations"}}`,
+	"Noun spec":                 `{{plural .Count "spec" "specs"}}`,
+	"Noun case":                 `{{plural .Count "case" "cases"}}`,
+	"Number":                    `{{words .Value}}`,
+	"File missing":              `I can't find the file named {{.Name}}`,
+	"File package":              `package {{.Name}}`,
+	"File imports":              `imports`,
+	"File declarations":         `declarations`,
+	"ImportSpec":                `{{.Path}}{{if .Name}} as {{.Name}}{{end}}`,
+	"ValueSpec const":           `{{plural .Count "constant" "constants"}}`,
+	"ValueSpec var":             `{{plural .Count "var" "vars"}}`,
+	"ValueSpec type":            `of type`,
+	"ValueSpec equals":          `equals`,
+	"TypeSpec":                  `type {{.Name}}`,
+	"TypeSpec is":               `is`,
+	"TypeParams":                `with {{plural .Count "type parameter" "type parameters"}}`,
+	"TypeParams and":            `and`,
+	"TypeParam constraint":      `{{if gt .Count 1}}all {{end}}constrained by`,
+	"FuncDecl":                  `function {{.Name}}`,
+	"FuncDecl receiver":         `with {{count .Count "receiver"}}`,
+	"FuncDecl start":            `function body`,
+	"FuncDecl end":              `end function {{.Name}}`,
+	"FuncType":                  `function`,
+	"FuncType params":           `taking {{count .Count "parameter"}}`,
+	"FuncType results":          `and returning {{count .Count "value"}}`,
+	"FuncLit":                   `lambda`,
+	"FuncLit start":             `is`,
+	"FuncLit end":               `end lambda`,
+	"Field type":                `{{if gt .Count 1}}all {{end}}as`,
+	"Field tag":                 `with tag`,
+	"ArrayType slice":           `slice of`,
+	"ArrayType":                 ``,
+	"ArrayType length":          `element array of`,
+	"StarExpr type":             `pointer to`,
+	"StarExpr":                  `contents of`,
+	"MapType":                   `map`,
+	"MapType key start":         `with`,
+	"MapType key":               `key`,
+	"MapType value start":       `and`,
+	"MapType value":             `value`,
+	"SelectorExpr":              `dot`,
+	"BinaryExpr union":          `or`,
+	"BinaryExpr ||":             `or`,
+	"BinaryExpr &&":             `and`,
+	"BinaryExpr ==":             `equals`,
+	"BinaryExpr !=":             `does not equal`,
+	"BinaryExpr <":              `is less than`,
+	"BinaryExpr <=":             `is less than or equal to`,
+	"BinaryExpr >":              `is greater than`,
+	"BinaryExpr >=":             `is greater than or equal to`,
+	"BinaryExpr +":              `plus`,
+	"BinaryExpr -":              `minus`,
+	"BinaryExpr |":              `bitwise or`,
+	"BinaryExpr ^":              `exclusive or`,
+	"BinaryExpr *":              `times`,
+	"BinaryExpr /":              `divided by`,
+	"BinaryExpr %":              `modulo`,
+	"BinaryExpr <<":             `shifted left by`,
+	"BinaryExpr >>":             `shifted right by`,
+	"BinaryExpr &":              `bitwise and`,
+	"BinaryExpr &^":             `bitwise and not`,
+	"UnaryExpr +":               `positive`,
+	"UnaryExpr -":               `negative`,
+	"UnaryExpr !":               `not`,
+	"UnaryExpr ^":               `bitwise not`,
+	"UnaryExpr *":               `star`,
+	"UnaryExpr &":               `ref`,
+	"UnaryExpr <-":              `receive from channel`,
+	"UnaryExpr ~":               `underlying type`,
+	"ParenExpr start":           `left paren`,
+	"ParenExpr end":             `right paren`,
+	"SliceExpr":                 `slice`,
+	"SliceExpr low":             `from`,
+	"SliceExpr no low":          `start`,
+	"SliceExpr high":            `to`,
+	"SliceExpr no high":         `to end`,
+	"SliceExpr max":             `with cap`,
+	"CompositeLit empty":        `empty`,
+	"CompositeLit empty after":  ``,
+	"CompositeLit elements":     `containing`,
+	"CompositeLit comma":        `comma`,
+	"KeyValueExpr":              `key`,
+	"KeyValueExpr value":        `with value`,
+	"IndexExpr type":            `of`,
+	"IndexExpr":                 `sub`,
+	"IndexListExpr":             `of`,
+	"IndexListExpr and":         `and`,
+	"InterfaceType empty":       `empty interface`,
+	"InterfaceType":             `interface`,
+	"InterfaceType type set":    `with type set`,
+	"InterfaceType embedded":    `embedding`,
+	"InterfaceType methods":     `having {{count .Count "method"}}`,
+	"StructType empty":          `empty struct`,
+	"StructType":                `struct`,
+	"StructType fields":         `having {{count .Count "field"}}`,
+	"TypeAssertExpr":            `as type`,
+	"ChanType send":             `send to channel`,
+	"ChanType receive":          `received from channel`,
+	"Ellipsis":                  `variable number of`,
+	"Ellipsis alone":            `variable number`,
+	"CallExpr no args":          `call`,
+	"CallExpr":                  `of`,
+	"CallExpr comma":            `comma`,
+	"CallExpr ellipsis":         `ellipsis`,
+	"BadDecl":                   `syntax error in declaration on line {{.Line}}`,
+	"BadExpr":                   `syntax error in expression on line {{.Line}}`,
+	"BadStmt":                   `syntax error in statement on line {{.Line}}`,
+	"BlockStmt start":           `begin block`,
+	"BlockStmt end":             `end block`,
+	"BlockStmt count":           `{{count .Count "statement"}}`,
+	"RangeStmt":                 `range over`,
+	"RangeStmt with":            `with`,
+	"RangeStmt key":             `key`,
+	"RangeStmt and":             `and`,
+	"RangeStmt value":           `value`,
+	"RangeStmt start":           `range body`,
+	"RangeStmt end":             `end range`,
+	"ReturnStmt":                `return`,
+	"ReturnStmt also":           `also`,
+	"BranchStmt break":          `break`,
+	"BranchStmt continue":       `continue`,
+	"BranchStmt goto":           `goto`,
+	"BranchStmt fallthrough":    `fallthrough`,
+	"BranchStmt label":          `at`,
+	"DeferStmt":                 `defer`,
+	"GoStmt":                    `go`,
+	"EmptyStmt":                 `empty`,
+	"IncDecStmt ++":             `increment`,
+	"IncDecStmt --":             `decrement`,
+	"LabeledStmt":               `label {{.Name}}`,
+	"SendStmt":                  `send`,
+	"SendStmt channel":          `to channel`,
+	"AssignStmt":                `let`,
+	"AssignStmt equal":          `equal`,
+	"AssignStmt operator":       `equal itself {{.Operator}}`,
+	"AssignStmt and":            `and`,
+	"IfStmt":                    `if`,
+	"IfStmt init":               `with initializer`,
+	"IfStmt cond":               `when`,
+	"IfStmt start":              `then`,
+	"IfStmt else":               `else`,
+	"IfStmt end":                `end if`,
+	"ForStmt forever":           `for ever`,
+	"ForStmt while":             `while`,
+	"ForStmt":                   `for`,
+	"ForStmt cond":              `while`,
+	"ForStmt start":             `do`,
+	"ForStmt end":               `end {{if .While}}while{{else}}for{{end}} loop`,
+	"SwitchStmt":                `switch`,
+	"SwitchStmt init":           `with initializer`,
+	"SwitchStmt tag":            `on`,
+	"SwitchStmt end":            `end switch`,
+	"TypeSwitchStmt":            `switch`,
+	"TypeSwitchStmt init":       `with initializer`,
+	"TypeSwitchStmt assign":     `on type`,
+	"TypeSwitchStmt end":        `end type switch`,
+	"CaseClause":                `case`,
+	"CaseClause default":        `default`,
+	"CaseClause or":             `or`,
+	"CommClause":                `case`,
+	"CommClause default":        `default`,
+	"SelectStmt":                `select`,
+	"SelectStmt end":            `end select`,
+	"BasicLit empty string":     `empty string`,
+	"BasicLit blanks":           `{{if eq .Count 1}}string with one blank{{else}}string of {{.Count}} blanks{{end}}`,
+	"BasicLit empty raw":        `empty raw string`,
+	"BasicLit raw lines":        `raw string spanning {{.Count}} lines`,
+	"BasicLit hex":              `hex {{.Digits}}`,
+	"BasicLit octal":            `octal {{.Digits}}`,
+	"BasicLit binary":           `binary {{.Digits}}`,
+	"BasicLit point":            `point`,
+	"BasicLit exponent":         `times ten to the {{.Exponent}}`,
+	"BasicLit binary exponent":  `times two to the {{.Exponent}}`,
+	"BasicLit negative":         `minus {{.Number}}`,
+	"BasicLit imaginary":        `imaginary {{.Number}}`,
+	"BasicLit character":        `character {{.Character}}`,
+	"BasicLit named character":  `{{.Name}} character`,
+	"BasicLit space":            `space character`,
+	"Escape a":                  `bell`,
+	"Escape b":                  `backspace`,
+	"Escape f":                  `form feed`,
+	"Escape n":                  `newline`,
+	"Escape r":                  `carriage return`,
+	"Escape t":                  `tab`,
+	"Escape v":                  `vertical tab`,
+	`Escape \`:                  `backslash`,
+	"Escape '":                  `single quote`,
+	`Escape "`:                  `double quote`,
+	"Escape hex":                `hex {{.Digits}}`,
+	"Escape unicode":            `unicode {{.Digits}}`,
+	"Escape octal":              `octal {{.Digits}}`,
+	"CommentGroup doc":          `doc comment`,
+	"CommentGroup":              `comment`,
+	"Outline method":            `method {{.Name}} on`,
+	"Outline type params":       `with {{count .Count "type parameter"}}`,
+	"Outline params":            `taking {{count .Count "parameter"}}`,
+	"Outline result":            `returning`,
+	"Outline results":           `returning {{count .Count "value"}}`,
+	"Outline struct":            `struct with {{count .Count "field"}}`,
+	"Outline interface":         `interface with {{count .Count "method"}}`,
+	"Package":                   `package {{.Name}}`,
+	"Package file":              `file {{.Name}}`,
+	"Type pointer":              `pointer to {{.Elem}}`,
+	"Type slice":                `slice of {{.Elem}}`,
+	"Type array":                `{{.Len}} element array of {{.Elem}}`,
+	"Type map":                  `map with {{.Key}} key and {{.Elem}} value`,
+	"Type channel":              `channel of {{.Elem}}`,
+	"Type function":             `function`,
+	"Type struct":               `struct`,
+	"Type named struct":         `struct {{.Name}}`,
+	"Type interface":            `interface`,
+	"Type empty interface":      `empty interface`,
+	"Type defined":              `{{article .Type}}`,
+	"SelectorExpr package":      `package`,
+	"SelectorExpr method":       `method {{.Name}} on`,
+	"SelectorExpr method value": `method value {{.Name}} of`,
+	"SelectorExpr receiver":     `of type {{.Type}}`,
+	"SelectorExpr field":        `field {{.Name}} of`,
+	"SelectorExpr owner":        `{{article .Type}}`,
+	"Error":                     `syntax error on line {{.Line}}, column {{.Column}}: {{.Message}}`,
+	"Error count":               `file has {{count .Count "syntax error"}}`,
+	"Error none":                `no syntax errors`,
+	"Error blank line":          `line {{.Line}} is blank`,
+	"Error line":                `line {{.Line}} reads`,
+	"Token {":                   `opening brace`,
+	"Token }":                   `closing brace`,
+	"Token (":                   `opening paren`,
+	"Token )":                   `closing paren`,
+	"Token [":                   `opening bracket`,
+	"Token ]":                   `closing bracket`,
+	"Token ;":                   `end of statement`,
+	"Token ,":                   `comma`,
+	"Token :":                   `colon`,
+	"Token .":                   `dot`,
+	"Token =":                   `equals`,
+	"Token :=":                  `colon equals`,
+	"Token EOF":                 `end of file`,
+	"Token IDENT":               `identifier`,
+	"Token STRING":              `string`,
+	"Token INT":                 `number`,
+	"Token FLOAT":               `number`,
+	"Token CHAR":                `character`,
+	"Token package":             `the package keyword`,
+	"Navigator empty":           `file has no declarations`,
+	"Navigator end":             `end of {{if .Block}}block{{else}}file{{end}}`,
+	"Navigator start":           `start of {{if .Block}}block{{else}}file{{end}}`,
+	"Navigator nothing inside":  `nothing inside`,
+	"Navigator top":             `already at top level`,
+	"Navigator not found":       `no declaration on line {{.Line}}`,
+	"Navigator function":        `in function {{.Name}}`,
+	"Navigator top level":       `at top level`,
+	"Navigator line":            `line {{.Line}}`,
+	"Navigator position":        `{{.Item}} {{.Index}} of {{.Count}}`,
+	"Navigator depth":           `nesting level {{.Depth}}`,
+	"Diff deleted":              `deleted file {{.Name}}`,
+	"Diff new":                  `new file {{.Name}}`,
+	"Diff file":                 `file {{.Name}}`,
+	"Diff lines":                `{{if eq .Start .End}}line {{.Start}}{{else}}lines {{.Start}} to {{.End}}{{end}}`,
+	"Diff function":             `{{if .Method}}method{{else}}function{{end}} {{.Name}}`,
+	"Diff added":                `added {{.Lines}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff removed":              `removed {{count .Count "line"}} before line {{.Line}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff modified":             `modified {{.Lines}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff replacing":            `replacing`,
+	"Diff held":                 `which held`,
+	"Diff statements":           `{{count .Count "statement"}} from {{.Function}}`,
+	"Diff line count":           `{{count .Count "line"}}`,
+	"Changes added":             `added {{.Declaration}}`,
+	"Changes changed":           `changed {{.Declaration}}`,
+	"Changes removed":           `removed {{.Declaration}}`,
+	"Changes none":              `no changes`,
+	"Changes imports":           `{{plural .Count "import" "imports"}}`,
+	"Changes const":             `{{plural .Count "constant" "constants"}} {{join .Names "and"}}`,
+	"Changes var":               `{{plural .Count "variable" "variables"}} {{join .Names "and"}}`,
+	"Changes type":              `{{plural .Count "type" "types"}} {{join .Names "and"}}`,
+}
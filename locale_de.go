@@ -0,0 +1,292 @@
+package gospeak
+
+var germanLocale = Locale{
+	"Language":                  `de-DE`,
+	"Count":                     `{{if eq .Count 0}}keine{{else}}{{.Count}}{{end}} {{.Noun}}`,
+	"Noun parameter":            `Parameter`,
+	"Noun type parameter":       `Typparameter`,
+	"Noun value":                `{{plural .Count "Wert" "Werte"}}`,
+	"Noun receiver":             `Empfänger`,
+	"Noun field":                `{{plural .Count "Feld" "Felder"}}`,
+	"Noun method":               `{{plural .Count "Methode" "Methoden"}}`,
+	"Noun statement":            `{{plural .Count "Anweisung" "Anweisungen"}}`,
+	"Noun line":                 `{{plural .Count "Zeile" "Zeilen"}}`,
+	"Noun file":                 `{{plural .Count "Datei" "Dateien"}}`,
+	"Noun exported type":        `{{plural .Count "exportierter Typ" "exportierten Typen"}}`,
+	"Noun exported function":    `{{plural .Count "exportierte Funktion" "exportierten Funktionen"}}`,
+	"Noun syntax error":         `Syntaxfehler`,
+	"Noun declaration":          `{{plural .Count "Deklaration" "Deklarationen"}}`,
+	"Noun spec":                 `{{plural .Count "Spezifikation" "Spezifikationen"}}`,
+	"Noun case":                 `{{plural .Count "Fall" "Fälle"}}`,
+	"Number":                    `{{.Value}}`,
+	"File missing":              `ich finde die Datei {{.Name}} nicht`,
+	"File package":              `Paket {{.Name}}`,
+	"File imports":              `importiert`,
+	"File declarations":         `Deklarationen`,
+	"ImportSpec":                `{{.Path}}{{if .Name}} als {{.Name}}{{end}}`,
+	"ValueSpec const":           `{{plural .Count "Konstante" "Konstanten"}}`,
+	"ValueSpec var":             `{{plural .Count "Variable" "Variablen"}}`,
+	"ValueSpec type":            `vom Typ`,
+	"ValueSpec equals":          `gleich`,
+	"TypeSpec":                  `Typ {{.Name}}`,
+	"TypeSpec is":               `ist`,
+	"TypeParams":                `mit {{plural .Count "Typparameter" "Typparametern"}}`,
+	"TypeParams and":            `und`,
+	"TypeParam constraint":      `{{if gt .Count 1}}alle {{end}}eingeschränkt durch`,
+	"FuncDecl":                  `Funktion {{.Name}}`,
+	"FuncDecl receiver":         `hat {{count .Count "receiver"}}`,
+	"FuncDecl start":            `Funktionsrumpf`,
+	"FuncDecl end":              `Ende der Funktion {{.Name}}`,
+	"FuncType":                  `Funktion`,
+	"FuncType params":           `nimmt {{count .Count "parameter"}}`,
+	"FuncType results":          `und liefert {{count .Count "value"}}`,
+	"FuncLit":                   `Lambda`,
+	"FuncLit start":             `ist`,
+	"FuncLit end":               `Ende des Lambdas`,
+	"Field type":                `{{if gt .Count 1}}alle {{end}}vom Typ`,
+	"Field tag":                 `mit Tag`,
+	"ArrayType slice":           `Slice von`,
+	"ArrayType":                 `Array mit`,
+	"ArrayType length":          `Elementen vom Typ`,
+	"StarExpr type":             `Zeiger auf`,
+	"StarExpr":                  `Inhalt von`,
+	"MapType":                   `Map`,
+	"MapType key start":         `mit Schlüssel`,
+	"MapType key":               ``,
+	"MapType value start":       `und Wert`,
+	"MapType value":             ``,
+	"SelectorExpr":              `Punkt`,
+	"BinaryExpr union":          `oder`,
+	"BinaryExpr ||":             `oder`,
+	"BinaryExpr &&":             `und`,
+	"BinaryExpr ==":             `gleich`,
+	"BinaryExpr !=":             `ungleich`,
+	"BinaryExpr <":              `kleiner als`,
+	"BinaryExpr <=":             `kleiner oder gleich`,
+	"BinaryExpr >":              `größer als`,
+	"BinaryExpr >=":             `größer oder gleich`,
+	"BinaryExpr +":              `plus`,
+	"BinaryExpr -":              `minus`,
+	"BinaryExpr |":              `bitweise oder`,
+	"BinaryExpr ^":              `exklusiv oder`,
+	"BinaryExpr *":              `mal`,
+	"BinaryExpr /":              `geteilt durch`,
+	"BinaryExpr %":              `modulo`,
+	"BinaryExpr <<":             `links verschoben um`,
+	"BinaryExpr >>":             `rechts verschoben um`,
+	"BinaryExpr &":              `bitweise und`,
+	"BinaryExpr &^":             `bitweise und nicht`,
+	"UnaryExpr +":               `positiv`,
+	"UnaryExpr -":               `negativ`,
+	"UnaryExpr !":               `nicht`,
+	"UnaryExpr ^":               `bitweise nicht`,
+	"UnaryExpr *":               `Stern`,
+	"UnaryExpr &":               `Referenz auf`,
+	"UnaryExpr <-":              `empfange von Kanal`,
+	"UnaryExpr ~":               `zugrunde liegender Typ`,
+	"ParenExpr start":           `Klammer auf`,
+	"ParenExpr end":             `Klammer zu`,
+	"SliceExpr":                 `Teil von`,
+	"SliceExpr low":             `ab`,
+	"SliceExpr no low":          `Anfang`,
+	"SliceExpr high":            `bis`,
+	"SliceExpr no high":         `bis Ende`,
+	"SliceExpr max":             `mit Kapazität`,
+	"CompositeLit empty":        `leeres`,
+	"CompositeLit empty after":  ``,
+	"CompositeLit elements":     `mit Inhalt`,
+	"CompositeLit comma":        `Komma`,
+	"KeyValueExpr":              `Schlüssel`,
+	"KeyValueExpr value":        `mit Wert`,
+	"IndexExpr type":            `von`,
+	"IndexExpr":                 `an Stelle`,
+	"IndexListExpr":             `von`,
+	"IndexListExpr and":         `und`,
+	"InterfaceType empty":       `leeres Interface`,
+	"InterfaceType":             `Interface`,
+	"InterfaceType type set":    `mit Typmenge`,
+	"InterfaceType embedded":    `bettet ein`,
+	"InterfaceType methods":     `hat {{count .Count "method"}}`,
+	"StructType empty":          `leere Struktur`,
+	"StructType":                `Struktur`,
+	"StructType fields":         `hat {{count .Count "field"}}`,
+	"TypeAssertExpr":            `als Typ`,
+	"ChanType send":             `sende an Kanal`,
+	"ChanType receive":          `empfangen von Kanal`,
+	"Ellipsis":                  `variable Anzahl von`,
+	"Ellipsis alone":            `variable Anzahl`,
+	"CallExpr no args":          `rufe`,
+	"CallExpr":                  `mit`,
+	"CallExpr comma":            `Komma`,
+	"CallExpr ellipsis":         `Auslassung`,
+	"BadDecl":                   `Syntaxfehler in einer Deklaration in Zeile {{.Line}}`,
+	"BadExpr":                   `Syntaxfehler in einem Ausdruck in Zeile {{.Line}}`,
+	"BadStmt":                   `Syntaxfehler in einer Anweisung in Zeile {{.Line}}`,
+	"BlockStmt start":           `Blockanfang`,
+	"BlockStmt end":             `Blockende`,
+	"BlockStmt count":           `{{count .Count "statement"}}`,
+	"RangeStmt":                 `durchlaufe`,
+	"RangeStmt with":            `mit`,
+	"RangeStmt key":             `Schlüssel`,
+	"RangeStmt and":             `und`,
+	"RangeStmt value":           `Wert`,
+	"RangeStmt start":           `Schleifenrumpf`,
+	"RangeStmt end":             `Ende der Schleife`,
+	"ReturnStmt":                `gib zurück`,
+	"ReturnStmt also":           `sowie`,
+	"BranchStmt break":          `break`,
+	"BranchStmt continue":       `continue`,
+	"BranchStmt goto":           `springe zu`,
+	"BranchStmt fallthrough":    `fallthrough`,
+	"BranchStmt label":          `bei`,
+	"DeferStmt":                 `verzögere`,
+	"GoStmt":                    `go`,
+	"EmptyStmt":                 `leer`,
+	"IncDecStmt ++":             `erhöhe`,
+	"IncDecStmt --":             `verringere`,
+	"LabeledStmt":               `Marke {{.Name}}`,
+	"SendStmt":                  `sende`,
+	"SendStmt channel":          `an Kanal`,
+	"AssignStmt":                `setze`,
+	"AssignStmt equal":          `gleich`,
+	"AssignStmt operator":       `gleich sich selbst {{.Operator}}`,
+	"AssignStmt and":            `und`,
+	"IfStmt":                    `wenn`,
+	"IfStmt init":               `mit Initialisierung`,
+	"IfStmt cond":               `falls`,
+	"IfStmt start":              `dann`,
+	"IfStmt else":               `sonst`,
+	"IfStmt end":                `Ende wenn`,
+	"ForStmt forever":           `endlos`,
+	"ForStmt while":             `solange`,
+	"ForStmt":                   `für`,
+	"ForStmt cond":              `solange`,
+	"ForStmt start":             `tue`,
+	"ForStmt end":               `Ende der {{if .While}}Solange{{else}}Für{{end}}-Schleife`,
+	"SwitchStmt":                `unterscheide`,
+	"SwitchStmt init":           `mit Initialisierung`,
+	"SwitchStmt tag":            `nach`,
+	"SwitchStmt end":            `Ende der Unterscheidung`,
+	"TypeSwitchStmt":            `unterscheide`,
+	"TypeSwitchStmt init":       `mit Initialisierung`,
+	"TypeSwitchStmt assign":     `nach Typ`,
+	"TypeSwitchStmt end":        `Ende der Typunterscheidung`,
+	"CaseClause":                `Fall`,
+	"CaseClause default":        `sonst`,
+	"CaseClause or":             `oder`,
+	"CommClause":                `Fall`,
+	"CommClause default":        `sonst`,
+	"SelectStmt":                `wähle`,
+	"SelectStmt end":            `Ende der Auswahl`,
+	"BasicLit empty string":     `leere Zeichenkette`,
+	"BasicLit blanks":           `{{if eq .Count 1}}Zeichenkette mit einem Leerzeichen{{else}}Zeichenkette mit {{.Count}} Leerzeichen{{end}}`,
+	"BasicLit empty raw":        `leere Rohzeichenkette`,
+	"BasicLit raw lines":        `Rohzeichenkette über {{.Count}} Zeilen`,
+	"BasicLit hex":              `hexadezimal {{.Digits}}`,
+	"BasicLit octal":            `oktal {{.Digits}}`,
+	"BasicLit binary":           `binär {{.Digits}}`,
+	"BasicLit point":            `Komma`,
+	"BasicLit exponent":         `mal zehn hoch {{.Exponent}}`,
+	"BasicLit binary exponent":  `mal zwei hoch {{.Exponent}}`,
+	"BasicLit negative":         `minus {{.Number}}`,
+	"BasicLit imaginary":        `imaginär {{.Number}}`,
+	"BasicLit character":        `Zeichen {{.Character}}`,
+	"BasicLit named character":  `Zeichen {{.Name}}`,
+	"BasicLit space":            `Leerzeichen`,
+	"Escape a":                  `Klingelzeichen`,
+	"Escape b":                  `Rückschritt`,
+	"Escape f":                  `Seitenvorschub`,
+	"Escape n":                  `Zeilenumbruch`,
+	"Escape r":                  `Wagenrücklauf`,
+	"Escape t":                  `Tabulator`,
+	"Escape v":                  `vertikaler Tabulator`,
+	`Escape \`:                  `Backslash`,
+	"Escape '":                  `einfaches Anführungszeichen`,
+	`Escape "`:                  `doppeltes Anführungszeichen`,
+	"Escape hex":                `hexadezimal {{.Digits}}`,
+	"Escape unicode":            `Unicode {{.Digits}}`,
+	"Escape octal":              `oktal {{.Digits}}`,
+	"CommentGroup doc":          `Dokumentationskommentar`,
+	"CommentGroup":              `Kommentar`,
+	"Outline method":            `Methode {{.Name}} von`,
+	"Outline type params":       `hat {{count .Count "type parameter"}}`,
+	"Outline params":            `nimmt {{count .Count "parameter"}}`,
+	"Outline result":            `liefert`,
+	"Outline results":           `liefert {{count .Count "value"}}`,
+	"Outline struct":            `Struktur, hat {{count .Count "field"}}`,
+	"Outline interface":         `Interface, hat {{count .Count "method"}}`,
+	"Package":                   `Paket {{.Name}}`,
+	"Package file":              `Datei {{.Name}}`,
+	"Type pointer":              `Zeiger auf {{.Elem}}`,
+	"Type slice":                `Slice von {{.Elem}}`,
+	"Type array":                `Array mit {{.Len}} Elementen vom Typ {{.Elem}}`,
+	"Type map":                  `Map mit Schlüssel {{.Key}} und Wert {{.Elem}}`,
+	"Type channel":              `Kanal von {{.Elem}}`,
+	"Type function":             `Funktion`,
+	"Type struct":               `Struktur`,
+	"Type named struct":         `Struktur {{.Name}}`,
+	"Type interface":            `Interface`,
+	"Type empty interface":      `leeres Interface`,
+	"Type defined":              `vom Typ {{.Type}}`,
+	"SelectorExpr package":      `Paket`,
+	"SelectorExpr method":       `Methode {{.Name}} von`,
+	"SelectorExpr method value": `Methodenwert {{.Name}} von`,
+	"SelectorExpr receiver":     `vom Typ {{.Type}}`,
+	"SelectorExpr field":        `Feld {{.Name}} von`,
+	"SelectorExpr owner":        `vom Typ {{.Type}}`,
+	"Error":                     `Syntaxfehler in Zeile {{.Line}}, Spalte {{.Column}}: {{.Message}}`,
+	"Error count":               `die Datei hat {{count .Count "syntax error"}}`,
+	"Error none":                `keine Syntaxfehler`,
+	"Error blank line":          `Zeile {{.Line}} ist leer`,
+	"Error line":                `Zeile {{.Line}} lautet`,
+	"Token {":                   `öffnende geschweifte Klammer`,
+	"Token }":                   `schließende geschweifte Klammer`,
+	"Token (":                   `öffnende Klammer`,
+	"Token )":                   `schließende Klammer`,
+	"Token [":                   `öffnende eckige Klammer`,
+	"Token ]":                   `schließende eckige Klammer`,
+	"Token ;":                   `Ende der Anweisung`,
+	"Token ,":                   `Komma`,
+	"Token :":                   `Doppelpunkt`,
+	"Token .":                   `Punkt`,
+	"Token =":                   `Gleichheitszeichen`,
+	"Token :=":                  `Doppelpunkt gleich`,
+	"Token EOF":                 `Dateiende`,
+	"Token IDENT":               `Bezeichner`,
+	"Token STRING":              `Zeichenkette`,
+	"Token INT":                 `Zahl`,
+	"Token FLOAT":               `Zahl`,
+	"Token CHAR":                `Zeichen`,
+	"Token package":             `das Schlüsselwort package`,
+	"Navigator empty":           `die Datei hat keine Deklarationen`,
+	"Navigator end":             `Ende {{if .Block}}des Blocks{{else}}der Datei{{end}}`,
+	"Navigator start":           `Anfang {{if .Block}}des Blocks{{else}}der Datei{{end}}`,
+	"Navigator nothing inside":  `nichts darin`,
+	"Navigator top":             `schon auf oberster Ebene`,
+	"Navigator not found":       `keine Deklaration in Zeile {{.Line}}`,
+	"Navigator function":        `in Funktion {{.Name}}`,
+	"Navigator top level":       `auf oberster Ebene`,
+	"Navigator line":            `Zeile {{.Line}}`,
+	"Navigator position":        `{{.Item}} {{.Index}} von {{.Count}}`,
+	"Navigator depth":           `Verschachtelungstiefe {{.Depth}}`,
+	"Diff deleted":              `gelöschte Datei {{.Name}}`,
+	"Diff new":                  `neue Datei {{.Name}}`,
+	"Diff file":                 `Datei {{.Name}}`,
+	"Diff lines":                `{{if eq .Start .End}}Zeile {{.Start}}{{else}}Zeilen {{.Start}} bis {{.End}}{{end}}`,
+	"Diff function":             `{{if .Method}}Methode{{else}}Funktion{{end}} {{.Name}}`,
+	"Diff added":                `hinzugefügt {{.Lines}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff removed":              `entfernt {{count .Count "line"}} vor Zeile {{.Line}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff modified":             `geändert {{.Lines}}{{if .Function}} in {{.Function}}{{end}}`,
+	"Diff replacing":            `ersetzt`,
+	"Diff held":                 `enthielt`,
+	"Diff statements":           `{{count .Count "statement"}} aus {{.Function}}`,
+	"Diff line count":           `{{count .Count "line"}}`,
+	"Changes added":             `hinzugefügt {{.Declaration}}`,
+	"Changes changed":           `geändert {{.Declaration}}`,
+	"Changes removed":           `entfernt {{.Declaration}}`,
+	"Changes none":              `keine Änderungen`,
+	"Changes imports":           `{{plural .Count "Import" "Importe"}}`,
+	"Changes const":             `{{plural .Count "Konstante" "Konstanten"}} {{join .Names "und"}}`,
+	"Changes var":               `{{plural .Count "Variable" "Variablen"}} {{join .Names "und"}}`,
+	"Changes type":              `{{plural .Count "Typ" "Typen"}} {{join .Names "und"}}`,
+}
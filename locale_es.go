@@ -0,0 +1,292 @@
+package gospeak
+
+var spanishLocale = Locale{
+	"Language":                  `es-ES`,
+	"Count":                     `{{if eq .Count 0}}cero{{else}}{{.Count}}{{end}} {{.Noun}}`,
+	"Noun parameter":            `{{plural .Count "parámetro" "parámetros"}}`,
+	"Noun type parameter":       `{{plural .Count "parámetro de tipo" "parámetros de tipo"}}`,
+	"Noun value":                `{{plural .Count "valor" "valores"}}`,
+	"Noun receiver":             `{{plural .Count "receptor" "receptores"}}`,
+	"Noun field":                `{{plural .Count "campo" "campos"}}`,
+	"Noun method":               `{{plural .Count "método" "métodos"}}`,
+	"Noun statement":            `{{plural .Count "sentencia" "sentencias"}}`,
+	"Noun line":                 `{{plural .Count "línea" "líneas"}}`,
+	"Noun file":                 `{{plural .Count "archivo" "archivos"}}`,
+	"Noun exported type":        `{{plural .Count "tipo exportado" "tipos exportados"}}`,
+	"Noun exported function":    `{{plural .Count "función exportada" "funciones exportadas"}}`,
+	"Noun syntax error":         `{{plural .Count "error de sintaxis" "errores de sintaxis"}}`,
+	"Noun declaration":          `{{plural .Count "declaración" "declaraciones"}}`,
+	"Noun spec":                 `{{plural .Count "especificación" "especificaciones"}}`,
+	"Noun case":                 `{{plural .Count "caso" "casos"}}`,
+	"Number":                    `{{.Value}}`,
+	"File missing":              `no encuentro el archivo {{.Name}}`,
+	"File package":              `paquete {{.Name}}`,
+	"File imports":              `importa`,
+	"File declarations":         `declaraciones`,
+	"ImportSpec":                `{{.Path}}{{if .Name}} como {{.Name}}{{end}}`,
+	"ValueSpec const":           `{{plural .Count "constante" "constantes"}}`,
+	"ValueSpec var":             `{{plural .Count "variable" "variables"}}`,
+	"ValueSpec type":            `de tipo`,
+	"ValueSpec equals":          `igual a`,
+	"TypeSpec":                  `tipo {{.Name}}`,
+	"TypeSpec is":               `es`,
+	"TypeParams":                `con {{plural .Count "parámetro de tipo" "parámetros de tipo"}}`,
+	"TypeParams and":            `y`,
+	"TypeParam constraint":      `{{if gt .Count 1}}todos restringidos{{else}}restringido{{end}} por`,
+	"FuncDecl":                  `función {{.Name}}`,
+	"FuncDecl receiver":         `con {{count .Count "receiver"}}`,
+	"FuncDecl start":            `cuerpo de la función`,
+	"FuncDecl end":              `fin de la función {{.Name}}`,
+	"FuncType":                  `función`,
+	"FuncType params":           `que recibe {{count .Count "parameter"}}`,
+	"FuncType results":          `y devuelve {{count .Count "value"}}`,
+	"FuncLit":                   `lambda`,
+	"FuncLit start":             `que es`,
+	"FuncLit end":               `fin de la lambda`,
+	"Field type":                `{{if gt .Count 1}}todos {{end}}de tipo`,
+	"Field tag":                 `con etiqueta`,
+	"ArrayType slice":           `slice de`,
+	"ArrayType":                 `arreglo de`,
+	"ArrayType length":          `elementos de`,
+	"StarExpr type":             `puntero a`,
+	"StarExpr":                  `contenido de`,
+	"MapType":                   `mapa`,
+	"MapType key start":         `con clave`,
+	"MapType key":               ``,
+	"MapType value start":       `y valor`,
+	"MapType value":             ``,
+	"SelectorExpr":              `punto`,
+	"BinaryExpr union":          `o`,
+	"BinaryExpr ||":             `o`,
+	"BinaryExpr &&":             `y`,
+	"BinaryExpr ==":             `es igual a`,
+	"BinaryExpr !=":             `no es igual a`,
+	"BinaryExpr <":              `es menor que`,
+	"BinaryExpr <=":             `es menor o igual que`,
+	"BinaryExpr >":              `es mayor que`,
+	"BinaryExpr >=":             `es mayor o igual que`,
+	"BinaryExpr +":              `más`,
+	"BinaryExpr -":              `menos`,
+	"BinaryExpr |":              `o bit a bit`,
+	"BinaryExpr ^":              `o exclusivo`,
+	"BinaryExpr *":              `por`,
+	"BinaryExpr /":              `dividido por`,
+	"BinaryExpr %":              `módulo`,
+	"BinaryExpr <<":             `desplazado a la izquierda`,
+	"BinaryExpr >>":             `desplazado a la derecha`,
+	"BinaryExpr &":              `y bit a bit`,
+	"BinaryExpr &^":             `y no bit a bit`,
+	"UnaryExpr +":               `positivo`,
+	"UnaryExpr -":               `negativo`,
+	"UnaryExpr !":               `no`,
+	"UnaryExpr ^":               `negación bit a bit`,
+	"UnaryExpr *":               `asterisco`,
+	"UnaryExpr &":               `referencia a`,
+	"UnaryExpr <-":              `recibir del canal`,
+	"UnaryExpr ~":               `tipo subyacente`,
+	"ParenExpr start":           `abre paréntesis`,
+	"ParenExpr end":             `cierra paréntesis`,
+	"SliceExpr":                 `porción de`,
+	"SliceExpr low":             `desde`,
+	"SliceExpr no low":          `el principio`,
+	"SliceExpr high":            `hasta`,
+	"SliceExpr no high":         `hasta el final`,
+	"SliceExpr max":             `con capacidad`,
+	"CompositeLit empty":        ``,
+	"CompositeLit empty after":  `vacío`,
+	"CompositeLit elements":     `que contiene`,
+	"CompositeLit comma":        `coma`,
+	"KeyValueExpr":              `clave`,
+	"KeyValueExpr value":        `con valor`,
+	"IndexExpr type":            `de`,
+	"IndexExpr":                 `en`,
+	"IndexListExpr":             `de`,
+	"IndexListExpr and":         `y`,
+	"InterfaceType empty":       `interfaz vacía`,
+	"InterfaceType":             `interfaz`,
+	"InterfaceType type set":    `con conjunto de tipos`,
+	"InterfaceType embedded":    `que incorpora`,
+	"InterfaceType methods":     `con {{count .Count "method"}}`,
+	"StructType empty":          `estructura vacía`,
+	"StructType":                `estructura`,
+	"StructType fields":         `con {{count .Count "field"}}`,
+	"TypeAssertExpr":            `como tipo`,
+	"ChanType send":             `enviar al canal`,
+	"ChanType receive":          `recibido del canal`,
+	"Ellipsis":                  `número variable de`,
+	"Ellipsis alone":            `número variable`,
+	"CallExpr no args":          `llamar a`,
+	"CallExpr":                  `con`,
+	"CallExpr comma":            `coma`,
+	"CallExpr ellipsis":         `puntos suspensivos`,
+	"BadDecl":                   `error de sintaxis en una declaración en la línea {{.Line}}`,
+	"BadExpr":                   `error de sintaxis en una expresión en la línea {{.Line}}`,
+	"BadStmt":                   `error de sintaxis en una sentencia en la línea {{.Line}}`,
+	"BlockStmt start":           `inicio de bloque`,
+	"BlockStmt end":             `fin de bloque`,
+	"BlockStmt count":           `{{count .Count "statement"}}`,
+	"RangeStmt":                 `recorrer`,
+	"RangeStmt with":            `con`,
+	"RangeStmt key":             `clave`,
+	"RangeStmt and":             `y`,
+	"RangeStmt value":           `valor`,
+	"RangeStmt start":           `cuerpo del recorrido`,
+	"RangeStmt end":             `fin del recorrido`,
+	"ReturnStmt":                `devolver`,
+	"ReturnStmt also":           `y también`,
+	"BranchStmt break":          `break`,
+	"BranchStmt continue":       `continue`,
+	"BranchStmt goto":           `ir a`,
+	"BranchStmt fallthrough":    `fallthrough`,
+	"BranchStmt label":          `en`,
+	"DeferStmt":                 `diferir`,
+	"GoStmt":                    `go`,
+	"EmptyStmt":                 `vacía`,
+	"IncDecStmt ++":             `incrementar`,
+	"IncDecStmt --":             `decrementar`,
+	"LabeledStmt":               `etiqueta {{.Name}}`,
+	"SendStmt":                  `enviar`,
+	"SendStmt channel":          `al canal`,
+	"AssignStmt":                `sea`,
+	"AssignStmt equal":          `igual a`,
+	"AssignStmt operator":       `igual a sí mismo {{.Operator}}`,
+	"AssignStmt and":            `y`,
+	"IfStmt":                    `si`,
+	"IfStmt init":               `con inicializador`,
+	"IfStmt cond":               `cuando`,
+	"IfStmt start":              `entonces`,
+	"IfStmt else":               `si no`,
+	"IfStmt end":                `fin del si`,
+	"ForStmt forever":           `para siempre`,
+	"ForStmt while":             `mientras`,
+	"ForStmt":                   `para`,
+	"ForStmt cond":              `mientras`,
+	"ForStmt start":             `hacer`,
+	"ForStmt end":               `fin del bucle {{if .While}}mientras{{else}}para{{end}}`,
+	"SwitchStmt":                `según`,
+	"SwitchStmt init":           `con inicializador`,
+	"SwitchStmt tag":            ``,
+	"SwitchStmt end":            `fin del según`,
+	"TypeSwitchStmt":            `según`,
+	"TypeSwitchStmt init":       `con inicializador`,
+	"TypeSwitchStmt assign":     `el tipo de`,
+	"TypeSwitchStmt end":        `fin del según tipo`,
+	"CaseClause":                `caso`,
+	"CaseClause default":        `por defecto`,
+	"CaseClause or":             `o`,
+	"CommClause":                `caso`,
+	"CommClause default":        `por defecto`,
+	"SelectStmt":                `seleccionar`,
+	"SelectStmt end":            `fin del seleccionar`,
+	"BasicLit empty string":     `cadena vacía`,
+	"BasicLit blanks":           `{{if eq .Count 1}}cadena con un espacio{{else}}cadena de {{.Count}} espacios{{end}}`,
+	"BasicLit empty raw":        `cadena literal vacía`,
+	"BasicLit raw lines":        `cadena literal de {{.Count}} líneas`,
+	"BasicLit hex":              `hexadecimal {{.Digits}}`,
+	"BasicLit octal":            `octal {{.Digits}}`,
+	"BasicLit binary":           `binario {{.Digits}}`,
+	"BasicLit point":            `punto`,
+	"BasicLit exponent":         `por diez a la {{.Exponent}}`,
+	"BasicLit binary exponent":  `por dos a la {{.Exponent}}`,
+	"BasicLit negative":         `menos {{.Number}}`,
+	"BasicLit imaginary":        `imaginario {{.Number}}`,
+	"BasicLit character":        `carácter {{.Character}}`,
+	"BasicLit named character":  `carácter {{.Name}}`,
+	"BasicLit space":            `carácter espacio`,
+	"Escape a":                  `campana`,
+	"Escape b":                  `retroceso`,
+	"Escape f":                  `salto de página`,
+	"Escape n":                  `salto de línea`,
+	"Escape r":                  `retorno de carro`,
+	"Escape t":                  `tabulador`,
+	"Escape v":                  `tabulador vertical`,
+	`Escape \`:                  `barra invertida`,
+	"Escape '":                  `comilla simple`,
+	`Escape "`:                  `comilla doble`,
+	"Escape hex":                `hexadecimal {{.Digits}}`,
+	"Escape unicode":            `unicode {{.Digits}}`,
+	"Escape octal":              `octal {{.Digits}}`,
+	"CommentGroup doc":          `comentario de documentación`,
+	"CommentGroup":              `comentario`,
+	"Outline method":            `método {{.Name}} de`,
+	"Outline type params":       `con {{count .Count "type parameter"}}`,
+	"Outline params":            `que recibe {{count .Count "parameter"}}`,
+	"Outline result":            `que devuelve`,
+	"Outline results":           `que devuelve {{count .Count "value"}}`,
+	"Outline struct":            `estructura con {{count .Count "field"}}`,
+	"Outline interface":         `interfaz con {{count .Count "method"}}`,
+	"Package":                   `paquete {{.Name}}`,
+	"Package file":              `archivo {{.Name}}`,
+	"Type pointer":              `puntero a {{.Elem}}`,
+	"Type slice":                `slice de {{.Elem}}`,
+	"Type array":                `arreglo de {{.Len}} elementos de {{.Elem}}`,
+	"Type map":                  `mapa con clave {{.Key}} y valor {{.Elem}}`,
+	"Type channel":              `canal de {{.Elem}}`,
+	"Type function":             `función`,
+	"Type struct":               `estructura`,
+	"Type named struct":         `estructura {{.Name}}`,
+	"Type interface":            `interfaz`,
+	"Type empty interface":      `interfaz vacía`,
+	"Type defined":              `de tipo {{.Type}}`,
+	"SelectorExpr package":      `paquete`,
+	"SelectorExpr method":       `método {{.Name}} de`,
+	"SelectorExpr method value": `valor del método {{.Name}} de`,
+	"SelectorExpr receiver":     `de tipo {{.Type}}`,
+	"SelectorExpr field":        `campo {{.Name}} de`,
+	"SelectorExpr owner":        `de tipo {{.Type}}`,
+	"Error":                     `error de sintaxis en la línea {{.Line}}, columna {{.Column}}: {{.Message}}`,
+	"Error count":               `el archivo tiene {{count .Count "syntax error"}}`,
+	"Error none":                `no hay errores de sintaxis`,
+	"Error blank line":          `la línea {{.Line}} está en blanco`,
+	"Error line":                `la línea {{.Line}} dice`,
+	"Token {":                   `llave de apertura`,
+	"Token }":                   `llave de cierre`,
+	"Token (":                   `paréntesis de apertura`,
+	"Token )":                   `paréntesis de cierre`,
+	"Token [":                   `corchete de apertura`,
+	"Token ]":                   `corchete de cierre`,
+	"Token ;":                   `fin de sentencia`,
+	"Token ,":                   `coma`,
+	"Token :":                   `dos puntos`,
+	"Token .":                   `punto`,
+	"Token =":                   `igual`,
+	"Token :=":                  `dos puntos igual`,
+	"Token EOF":                 `fin del archivo`,
+	"Token IDENT":               `identificador`,
+	"Token STRING":              `cadena`,
+	"Token INT":                 `número`,
+	"Token FLOAT":               `número`,
+	"Token CHAR":                `carácter`,
+	"Token package":             `la palabra clave package`,
+	"Navigator empty":           `el archivo no tiene declaraciones`,
+	"Navigator end":             `fin del {{if .Block}}bloque{{else}}archivo{{end}}`,
+	"Navigator start":           `inicio del {{if .Block}}bloque{{else}}archivo{{end}}`,
+	"Navigator nothing inside":  `no hay nada dentro`,
+	"Navigator top":             `ya en el nivel superior`,
+	"Navigator not found":       `no hay ninguna declaración en la línea {{.Line}}`,
+	"Navigator function":        `en la función {{.Name}}`,
+	"Navigator top level":       `en el nivel superior`,
+	"Navigator line":            `línea {{.Line}}`,
+	"Navigator position":        `{{.Item}} {{.Index}} de {{.Count}}`,
+	"Navigator depth":           `nivel de anidamiento {{.Depth}}`,
+	"Diff deleted":              `archivo eliminado {{.Name}}`,
+	"Diff new":                  `archivo nuevo {{.Name}}`,
+	"Diff file":                 `archivo {{.Name}}`,
+	"Diff lines":                `{{if eq .Start .End}}la línea {{.Start}}{{else}}las líneas {{.Start}} a {{.End}}{{end}}`,
+	"Diff function":             `{{if .Method}}el método{{else}}la función{{end}} {{.Name}}`,
+	"Diff added":                `se añadieron {{.Lines}}{{if .Function}} en {{.Function}}{{end}}`,
+	"Diff removed":              `se eliminaron {{count .Count "line"}} antes de la línea {{.Line}}{{if .Function}} en {{.Function}}{{end}}`,
+	"Diff modified":             `se modificaron {{.Lines}}{{if .Function}} en {{.Function}}{{end}}`,
+	"Diff replacing":            `que reemplazan`,
+	"Diff held":                 `que contenían`,
+	"Diff statements":           `{{count .Count "statement"}} de {{.Function}}`,
+	"Diff line count":           `{{count .Count "line"}}`,
+	"Changes added":             `se añadió {{.Declaration}}`,
+	"Changes changed":           `se modificó {{.Declaration}}`,
+	"Changes removed":           `se eliminó {{.Declaration}}`,
+	"Changes none":              `no hay cambios`,
+	"Changes imports":           `{{plural .Count "la importación" "las importaciones"}}`,
+	"Changes const":             `{{plural .Count "la constante" "las constantes"}} {{join .Names "y"}}`,
+	"Changes var":               `{{plural .Count "la variable" "las variables"}} {{join .Names "y"}}`,
+	"Changes type":              `{{plural .Count "el tipo" "los tipos"}} {{join .Names "y"}}`,
+}
@@ -0,0 +1,37 @@
+package gospeak
+
+// Logger receives warnings and, when verbose output is on, diagnostic
+// traces. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+type nopLogger struct {
+}
+
+func (nl nopLogger) Printf(format string, v ...interface{}) {
+}
+
+func (gsp *goSpeaker) SetLogger(logger Logger) {
+	gsp.logger = logger
+}
+
+func (gsp *goSpeaker) logf(format string, v ...interface{}) {
+	if gsp.logger == nil {
+		return
+	}
+	gsp.logger.Printf(format, v...)
+}
+
+// logError logs the error of a method that doesn't return it.
+func (gsp *goSpeaker) logError(err error) {
+	if err != nil {
+		gsp.logf("Error: %+v\n", err)
+	}
+}
+
+func (gsp *goSpeaker) tracef(format string, v ...interface{}) {
+	if gsp.verboseOutput {
+		gsp.logf(format, v...)
+	}
+}
@@ -0,0 +1,403 @@
+// Package lsp serves gospeak over the Language Server Protocol, so that any
+// editor with an LSP client can read Go code aloud. Besides the usual
+// document synchronization, the server answers three custom requests:
+//
+//	gospeak/speakSelection         speaks the lines of a range
+//	gospeak/speakFunctionAtCursor  speaks the function around a position
+//	gospeak/describeNode           speaks the statement or declaration at a position
+//
+// Each can also be sent through workspace/executeCommand with the same
+// parameters as its only argument. The result holds the speech text, the
+// individual phrases with their positions, and the audio file the speech was
+// saved to, when one was asked for.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/wutka/gospeak"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	SpeakSelection        = "gospeak/speakSelection"
+	SpeakFunctionAtCursor = "gospeak/speakFunctionAtCursor"
+	DescribeNode          = "gospeak/describeNode"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// SpeakerFactory makes a speaker for one request. When audio is true the
+// speaker should save its speech to a new audio file and return the file's
+// name; otherwise it returns an empty name, and may speak aloud or be quiet.
+type SpeakerFactory func(audio bool) (gospeak.GoSpeaker, string, error)
+
+type Server struct {
+	newSpeaker SpeakerFactory
+	documents  map[string]string
+	out        io.Writer
+	shutdown   bool
+}
+
+func MakeServer(newSpeaker SpeakerFactory) *Server {
+	return &Server{
+		newSpeaker: newSpeaker,
+		documents:  map[string]string{},
+	}
+}
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type TextDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+// SpeechParams are the parameters of the gospeak requests. Range is used by
+// speakSelection and Position by the others.
+type SpeechParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Position     Position               `json:"position"`
+	Audio        bool                   `json:"audio,omitempty"`
+}
+
+// Phrase is one spoken phrase and where in the document it came from.
+type Phrase struct {
+	Text     string   `json:"text"`
+	Position Position `json:"position"`
+	Kind     string   `json:"kind,omitempty"`
+}
+
+type SpeechResult struct {
+	Speech    string   `json:"speech"`
+	Phrases   []Phrase `json:"phrases"`
+	AudioFile string   `json:"audioFile,omitempty"`
+}
+
+// Serve reads requests from r and writes responses to w until the client
+// sends exit or closes r.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		body, err := ReadMessage(in)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{codeParseError, err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		var result interface{}
+		var rerr *responseError
+		if s.shutdown {
+			rerr = &responseError{codeInvalidRequest, "server is shut down"}
+		} else {
+			result, rerr = s.handle(msg.Method, msg.Params)
+		}
+		if msg.ID == nil {
+			// Notifications get no response
+			continue
+		}
+		if err := s.reply(msg.ID, result, rerr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(method string, params json.RawMessage) (interface{}, *responseError) {
+	switch method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": 1,
+				"executeCommandProvider": map[string]interface{}{
+					"commands": []string{SpeakSelection, SpeakFunctionAtCursor, DescribeNode},
+				},
+			},
+			"serverInfo": map[string]string{"name": "gospeak"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			} `json:"textDocument"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{codeInvalidParams, err.Error()}
+		}
+		s.documents[p.TextDocument.URI] = p.TextDocument.Text
+		return nil, nil
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   TextDocumentIdentifier `json:"textDocument"`
+			ContentChanges []struct {
+				Text string `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{codeInvalidParams, err.Error()}
+		}
+		// The server asks for full document sync, so the last change is
+		// the whole text
+		if len(p.ContentChanges) > 0 {
+			s.documents[p.TextDocument.URI] = p.ContentChanges[len(p.ContentChanges)-1].Text
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument TextDocumentIdentifier `json:"textDocument"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{codeInvalidParams, err.Error()}
+		}
+		delete(s.documents, p.TextDocument.URI)
+		return nil, nil
+	case "workspace/executeCommand":
+		var p struct {
+			Command   string            `json:"command"`
+			Arguments []json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &responseError{codeInvalidParams, err.Error()}
+		}
+		if len(p.Arguments) != 1 {
+			return nil, &responseError{codeInvalidParams, p.Command + " takes one argument"}
+		}
+		return s.speech(p.Command, p.Arguments[0])
+	case SpeakSelection, SpeakFunctionAtCursor, DescribeNode:
+		return s.speech(method, params)
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/didSave":
+		return nil, nil
+	}
+	return nil, &responseError{codeMethodNotFound, "unknown method " + method}
+}
+
+func (s *Server) speech(method string, rawParams json.RawMessage) (interface{}, *responseError) {
+	var params SpeechParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, &responseError{codeInvalidParams, err.Error()}
+	}
+	text, err := s.documentText(params.TextDocument.URI)
+	if err != nil {
+		return nil, &responseError{codeInvalidParams, err.Error()}
+	}
+
+	speaker, audioFile, err := s.newSpeaker(params.Audio)
+	if err != nil {
+		return nil, &responseError{codeInternalError, err.Error()}
+	}
+	if err := speaker.LoadStringErr(text); err != nil {
+		return nil, &responseError{codeInternalError, err.Error()}
+	}
+
+	// LSP lines count from zero, gospeak lines from one
+	line := params.Position.Line + 1
+	switch method {
+	case SpeakSelection:
+		end := params.Range.End.Line + 1
+		if params.Range.End.Character == 0 && end > params.Range.Start.Line+1 {
+			// A selection ending at the start of a line doesn't include it
+			end--
+		}
+		err = speaker.SpeakRangeContext(context.Background(), params.Range.Start.Line+1, end)
+	case SpeakFunctionAtCursor:
+		start, end, ok := functionLines(text, line)
+		if !ok {
+			return nil, &responseError{codeInvalidParams, "cursor is not in a function"}
+		}
+		err = speaker.SpeakRangeContext(context.Background(), start, end)
+	case DescribeNode:
+		var nav *gospeak.Navigator
+		nav, err = speaker.Navigator()
+		if err == nil {
+			err = nav.Seek(line)
+		}
+	default:
+		return nil, &responseError{codeMethodNotFound, "unknown command " + method}
+	}
+	if err != nil {
+		return nil, &responseError{codeInternalError, err.Error()}
+	}
+
+	return speechResult(speaker.SpeechEvents(), audioFile, text), nil
+}
+
+// functionLines returns the lines of the function declaration around a
+// line. Methods of different types can have the same name, so the function
+// is found by where it is rather than by its name.
+func functionLines(text string, line int) (int, int, bool) {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, "", text, 0)
+	if file == nil {
+		return 0, 0, false
+	}
+	for _, decl := range file.Decls {
+		if fd, ok := decl.(*ast.FuncDecl); ok {
+			start, end := fset.Position(fd.Pos()).Line, fset.Position(fd.End()).Line
+			if start <= line && line <= end {
+				return start, end, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// character converts a gospeak column, a byte offset counted from one, to
+// an LSP character, which counts UTF-16 code units from zero.
+func character(lines []string, line int, column int) int {
+	if line < 1 || line > len(lines) {
+		return column - 1
+	}
+	text := lines[line-1]
+	if column-1 < len(text) {
+		text = text[:column-1]
+	}
+	return len(utf16.Encode([]rune(text)))
+}
+
+func speechResult(events []gospeak.SpeechEvent, audioFile string, text string) SpeechResult {
+	result := SpeechResult{
+		Phrases:   []Phrase{},
+		AudioFile: audioFile,
+	}
+	texts := []string{}
+	lines := strings.Split(text, "\n")
+	for _, e := range events {
+		text := strings.TrimSpace(e.Phrase)
+		if text == "" || e.IsEarcon() {
+			continue
+		}
+		texts = append(texts, text)
+		phrase := Phrase{Text: text, Kind: e.Kind}
+		if e.Start.Line > 0 {
+			phrase.Position = Position{Line: e.Start.Line - 1, Character: character(lines, e.Start.Line, e.Start.Column)}
+		}
+		result.Phrases = append(result.Phrases, phrase)
+	}
+	result.Speech = strings.Join(texts, ", ")
+	return result
+}
+
+// documentText returns the text of an open document, or reads it from disk
+// if the client hasn't opened it.
+func (s *Server) documentText(uri string) (string, error) {
+	if text, ok := s.documents[uri]; ok {
+		return text, nil
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("document %s is not open", uri)
+	}
+	source, err := ioutil.ReadFile(u.Path)
+	if err != nil {
+		return "", err
+	}
+	return string(source), nil
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) error {
+	msg := struct {
+		JSONRPC string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Result  interface{}      `json:"result"`
+		Error   *responseError   `json:"error,omitempty"`
+	}{JSONRPC: "2.0", ID: id, Result: result, Error: rerr}
+	if rerr != nil {
+		msg.Result = nil
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return WriteMessage(s.out, body)
+}
+
+// ReadMessage reads one message body framed with a Content-Length header.
+func ReadMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length < 0 {
+				return nil, io.EOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if i := strings.Index(line, ":"); i >= 0 && strings.EqualFold(line[:i], "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(line[i+1:]))
+			if err != nil {
+				return nil, fmt.Errorf("bad Content-Length: %+v", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("message has no Content-Length header")
+	}
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// WriteMessage writes a message body with its Content-Length header.
+func WriteMessage(w io.Writer, body []byte) error {
+	_, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
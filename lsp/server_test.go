@@ -0,0 +1,217 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/wutka/gospeak"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testProgram = `package main
+
+func main() {
+	x := 1
+	if x > 0 {
+		println(x)
+	}
+}
+
+func other() {
+}
+`
+
+// client is an in-process LSP client talking to a Server over pipes.
+type client struct {
+	t      *testing.T
+	in     *bufio.Reader
+	out    io.Writer
+	nextID int
+}
+
+func startServer(t *testing.T) (*client, chan error) {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	server := MakeServer(func(audio bool) (gospeak.GoSpeaker, string, error) {
+		return gospeak.MakeGoSpeaker(true, false, false, gospeak.VerbosityFull, "", nil), "", nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	return &client{t: t, in: bufio.NewReader(clientIn), out: clientOut}, done
+}
+
+func (c *client) send(method string, id int, params interface{}) {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}
+	if id > 0 {
+		msg["id"] = id
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatalf("Unable to encode %s: %+v", method, err)
+	}
+	if err := WriteMessage(c.out, body); err != nil {
+		c.t.Fatalf("Unable to send %s: %+v", method, err)
+	}
+}
+
+func (c *client) notify(method string, params interface{}) {
+	c.send(method, 0, params)
+}
+
+type response struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *responseError  `json:"error"`
+}
+
+func (c *client) call(method string, params interface{}) response {
+	c.nextID++
+	c.send(method, c.nextID, params)
+	body, err := ReadMessage(c.in)
+	if err != nil {
+		c.t.Fatalf("Unable to read response to %s: %+v", method, err)
+	}
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		c.t.Fatalf("Unable to decode response to %s: %+v", method, err)
+	}
+	if resp.ID != c.nextID {
+		c.t.Fatalf("Expected response %d, got %d", c.nextID, resp.ID)
+	}
+	return resp
+}
+
+func (c *client) speech(method string, params interface{}) SpeechResult {
+	resp := c.call(method, params)
+	if resp.Error != nil {
+		c.t.Fatalf("%s failed: %s", method, resp.Error.Message)
+	}
+	var result SpeechResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		c.t.Fatalf("Unable to decode %s result: %+v", method, err)
+	}
+	return result
+}
+
+func TestServer(t *testing.T) {
+	c, done := startServer(t)
+	doc := TextDocumentIdentifier{URI: "file:///tmp/main.go"}
+
+	resp := c.call("initialize", map[string]interface{}{"capabilities": map[string]interface{}{}})
+	if resp.Error != nil || !strings.Contains(string(resp.Result), SpeakSelection) {
+		t.Fatalf("Expected the gospeak commands in the capabilities, got %s", resp.Result)
+	}
+	c.notify("initialized", map[string]interface{}{})
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": doc.URI, "languageId": "go", "version": 1, "text": testProgram},
+	})
+
+	result := c.speech(SpeakSelection, SpeechParams{
+		TextDocument: doc,
+		Range:        Range{Start: Position{Line: 3}, End: Position{Line: 4, Character: 10}},
+	})
+	if !strings.HasPrefix(result.Speech, "let, x, equal, one, if, x, is greater than, zero") {
+		t.Errorf("Unexpected selection speech: %s", result.Speech)
+	}
+	if len(result.Phrases) == 0 || result.Phrases[1].Text != "x" || result.Phrases[1].Position != (Position{Line: 3, Character: 1}) {
+		t.Errorf("Unexpected phrases: %+v", result.Phrases)
+	}
+
+	result = c.speech(SpeakFunctionAtCursor, SpeechParams{TextDocument: doc, Position: Position{Line: 5}})
+	if !strings.Contains(result.Speech, "function main") || strings.Contains(result.Speech, "other") {
+		t.Errorf("Unexpected function speech: %s", result.Speech)
+	}
+
+	result = c.speech(DescribeNode, SpeechParams{TextDocument: doc, Position: Position{Line: 4}})
+	if result.Speech != "if, x, is greater than, zero, then, 1 statement" {
+		t.Errorf("Unexpected node description: %s", result.Speech)
+	}
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": doc.URI, "version": 2},
+		"contentChanges": []interface{}{map[string]interface{}{"text": strings.Replace(testProgram, "x := 1", "x := 2", 1)}},
+	})
+	result = c.speech("workspace/executeCommand", map[string]interface{}{
+		"command":   DescribeNode,
+		"arguments": []interface{}{SpeechParams{TextDocument: doc, Position: Position{Line: 3}}},
+	})
+	if result.Speech != "let, x, equal, two" {
+		t.Errorf("Expected the changed document, heard %s", result.Speech)
+	}
+
+	resp = c.call(SpeakFunctionAtCursor, SpeechParams{TextDocument: doc, Position: Position{Line: 0}})
+	if resp.Error == nil || resp.Error.Code != codeInvalidParams {
+		t.Errorf("Expected an error outside a function, got %+v", resp)
+	}
+	resp = c.call("textDocument/hover", SpeechParams{TextDocument: doc})
+	if resp.Error == nil || resp.Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", resp)
+	}
+
+	c.call("shutdown", nil)
+	c.notify("exit", nil)
+	if err := <-done; err != nil {
+		t.Errorf("Server failed: %+v", err)
+	}
+}
+
+const methodsProgram = `package main
+
+type first struct{}
+
+type second struct{}
+
+func (first) Name() string {
+	return "first"
+}
+
+func (second) Name() string {
+	s := "😀"; return s
+}
+`
+
+func TestServerPositions(t *testing.T) {
+	c, done := startServer(t)
+	doc := TextDocumentIdentifier{URI: "file:///tmp/methods.go"}
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": doc.URI, "languageId": "go", "version": 1, "text": methodsProgram},
+	})
+
+	// Both methods are called Name, but only the one at the cursor is read
+	result := c.speech(SpeakFunctionAtCursor, SpeechParams{TextDocument: doc, Position: Position{Line: 6}})
+	if !strings.Contains(result.Speech, "first") || strings.Contains(result.Speech, "second") {
+		t.Errorf("Unexpected method speech: %s", result.Speech)
+	}
+
+	// Characters are counted in UTF-16, where the emoji takes two
+	result = c.speech(SpeakSelection, SpeechParams{
+		TextDocument: doc,
+		Range:        Range{Start: Position{Line: 11}, End: Position{Line: 11, Character: 20}},
+	})
+	found := false
+	for _, phrase := range result.Phrases {
+		if phrase.Text == "return" {
+			found = true
+			if phrase.Position != (Position{Line: 11, Character: 12}) {
+				t.Errorf("Expected return at character 12, got %+v", phrase.Position)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("No return phrase in %+v", result.Phrases)
+	}
+
+	c.call("shutdown", nil)
+	c.notify("exit", nil)
+	if err := <-done; err != nil {
+		t.Errorf("Server failed: %+v", err)
+	}
+}
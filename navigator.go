@@ -0,0 +1,297 @@
+package gospeak
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Navigator walks a loaded file one node at a time, speaking only the node
+// under the cursor. Statements that contain blocks are summarized; Into
+// moves down into the block and Out returns to the enclosing node.
+type Navigator struct {
+	gsp  *goSpeaker
+	path []*navLevel
+}
+
+type navLevel struct {
+	parent ast.Node
+	nodes  []ast.Node
+	index  int
+}
+
+func (gsp *goSpeaker) Navigator() (*Navigator, error) {
+	if gsp.file == nil {
+		return nil, ErrNothingLoaded
+	}
+	top := &navLevel{
+		parent: gsp.file,
+	}
+	for _, d := range gsp.file.Decls {
+		top.nodes = append(top.nodes, d)
+	}
+	return &Navigator{
+		gsp:  gsp,
+		path: []*navLevel{top},
+	}, nil
+}
+
+func (nav *Navigator) level() *navLevel {
+	return nav.path[len(nav.path)-1]
+}
+
+// Current returns the node under the cursor, or nil if the file has no
+// declarations.
+func (nav *Navigator) Current() ast.Node {
+	level := nav.level()
+	if len(level.nodes) == 0 {
+		return nil
+	}
+	return level.nodes[level.index]
+}
+
+func (nav *Navigator) Repeat() error {
+	if nav.Current() == nil {
+		return nav.say("Navigator empty")
+	}
+	return nav.speakCurrent()
+}
+
+func (nav *Navigator) Next() error {
+	level := nav.level()
+	if level.index+1 >= len(level.nodes) {
+		return nav.say("Navigator end", "Block", len(nav.path) > 1)
+	}
+	level.index++
+	return nav.speakCurrent()
+}
+
+func (nav *Navigator) Previous() error {
+	level := nav.level()
+	if level.index == 0 {
+		return nav.say("Navigator start", "Block", len(nav.path) > 1)
+	}
+	level.index--
+	return nav.speakCurrent()
+}
+
+func (nav *Navigator) Into() error {
+	current := nav.Current()
+	if current == nil {
+		return nav.say("Navigator empty")
+	}
+	children := navChildren(current)
+	if len(children) == 0 {
+		return nav.say("Navigator nothing inside")
+	}
+	nav.path = append(nav.path, &navLevel{
+		parent: current,
+		nodes:  children,
+	})
+	return nav.speakCurrent()
+}
+
+func (nav *Navigator) Out() error {
+	if len(nav.path) == 1 {
+		return nav.say("Navigator top")
+	}
+	nav.path = nav.path[:len(nav.path)-1]
+	return nav.speakCurrent()
+}
+
+// Seek moves the cursor to the innermost declaration or statement on a
+// line and speaks it.
+func (nav *Navigator) Seek(line int) error {
+	gsp := nav.gsp
+	contains := func(n ast.Node) bool {
+		return gsp.fileSet.Position(n.Pos()).Line <= line && gsp.fileSet.Position(n.End()).Line >= line
+	}
+
+	nav.path = nav.path[:1]
+	level := nav.level()
+	found := false
+	for i, n := range level.nodes {
+		if contains(n) {
+			level.index = i
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nav.say("Navigator not found", "Line", line)
+	}
+
+	for {
+		children := navChildren(nav.Current())
+		index := -1
+		for i, n := range children {
+			if contains(n) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			break
+		}
+		nav.path = append(nav.path, &navLevel{
+			parent: nav.Current(),
+			nodes:  children,
+			index:  index,
+		})
+	}
+	return nav.speakCurrent()
+}
+
+func (nav *Navigator) Where() error {
+	current := nav.Current()
+	if current == nil {
+		return nav.say("Navigator empty")
+	}
+	gsp := nav.gsp
+	gsp.events = nil
+
+	if function := nav.function(); function != nil {
+		gsp.speakPhrase("Navigator function", "Name", gsp.symbolToSpeech(function.Name.String()))
+	} else {
+		gsp.speakPhrase("Navigator top level")
+	}
+	level := nav.level()
+	gsp.speakPhrase("Navigator line", "Line", gsp.fileSet.Position(current.Pos()).Line)
+	gsp.speakPhrase("Navigator position", "Item", gsp.phrase("Noun "+nav.itemName(), "Count", 1),
+		"Index", level.index+1, "Count", len(level.nodes))
+	if len(nav.path) > 1 {
+		gsp.speakPhrase("Navigator depth", "Depth", len(nav.path)-1)
+	}
+	return gsp.speakBuffer()
+}
+
+func (nav *Navigator) itemName() string {
+	switch nav.level().parent.(type) {
+	case *ast.File:
+		return "declaration"
+	case *ast.GenDecl:
+		return "spec"
+	case *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		return "case"
+	}
+	return "statement"
+}
+
+// function returns the function declaration the cursor is in, if any.
+func (nav *Navigator) function() *ast.FuncDecl {
+	for _, level := range nav.path {
+		if fd, ok := level.nodes[level.index].(*ast.FuncDecl); ok {
+			return fd
+		}
+	}
+	return nil
+}
+
+func (nav *Navigator) say(message string, fields ...interface{}) error {
+	nav.gsp.events = nil
+	nav.gsp.speakPhrase(message, fields...)
+	return nav.gsp.speakBuffer()
+}
+
+func (nav *Navigator) speakCurrent() error {
+	gsp := nav.gsp
+
+	savedStart, savedEnd, savedTarget := gsp.startLine, gsp.endLine, gsp.targetFunction
+	gsp.startLine, gsp.endLine, gsp.targetFunction = -1, -1, ""
+	gsp.shallow = true
+	gsp.functionStack = nil
+	if function := nav.function(); function != nil && function != nav.Current() {
+		gsp.functionStack = []string{function.Name.String()}
+	}
+	gsp.depth = len(nav.path) - 1
+	gsp.resetComments()
+	gsp.events = nil
+
+	nav.speakNode(nav.Current(), nav.level().parent)
+
+	gsp.shallow = false
+	gsp.functionStack = nil
+	gsp.depth = 0
+	gsp.startLine, gsp.endLine, gsp.targetFunction = savedStart, savedEnd, savedTarget
+
+	return gsp.speakBuffer()
+}
+
+func (nav *Navigator) speakNode(n ast.Node, parent ast.Node) {
+	gsp := nav.gsp
+	switch v := n.(type) {
+	case *ast.GenDecl:
+		if v.Tok == token.IMPORT {
+			imports := []*ast.ImportSpec{}
+			for _, spec := range v.Specs {
+				imports = append(imports, spec.(*ast.ImportSpec))
+			}
+			gsp.speakImportSpecs(imports)
+			return
+		}
+		gsp.speakDeclaration(v)
+	case ast.Decl:
+		gsp.speakDeclaration(v)
+	case *ast.TypeSpec:
+		gsp.speakTypeSpec(v)
+	case *ast.ValueSpec:
+		specType := "var"
+		if gd, ok := parent.(*ast.GenDecl); ok && gd.Tok == token.CONST {
+			specType = "const"
+		}
+		gsp.speakValueSpec(v, specType)
+	case *ast.ImportSpec:
+		gsp.speakImportSpecs([]*ast.ImportSpec{v})
+	case ast.Stmt:
+		if ifStmt, ok := parent.(*ast.IfStmt); ok && ifStmt.Else == n {
+			gsp.speakMarker("IfStmt else", gsp.phrase("IfStmt else"))
+		}
+		gsp.speakStmt(v)
+	}
+}
+
+// navChildren lists the nodes Into can move to from n.
+func navChildren(n ast.Node) []ast.Node {
+	children := []ast.Node{}
+	addStmts := func(stmts []ast.Stmt) {
+		for _, s := range stmts {
+			children = append(children, s)
+		}
+	}
+
+	switch v := n.(type) {
+	case *ast.FuncDecl:
+		if v.Body != nil {
+			addStmts(v.Body.List)
+		}
+	case *ast.GenDecl:
+		if len(v.Specs) > 1 {
+			for _, spec := range v.Specs {
+				children = append(children, spec)
+			}
+		}
+	case *ast.BlockStmt:
+		addStmts(v.List)
+	case *ast.IfStmt:
+		addStmts(v.Body.List)
+		if v.Else != nil {
+			children = append(children, v.Else)
+		}
+	case *ast.ForStmt:
+		addStmts(v.Body.List)
+	case *ast.RangeStmt:
+		addStmts(v.Body.List)
+	case *ast.SwitchStmt:
+		addStmts(v.Body.List)
+	case *ast.TypeSwitchStmt:
+		addStmts(v.Body.List)
+	case *ast.SelectStmt:
+		addStmts(v.Body.List)
+	case *ast.CaseClause:
+		addStmts(v.Body)
+	case *ast.CommClause:
+		addStmts(v.Body)
+	case *ast.LabeledStmt:
+		children = append(children, v.Stmt)
+	}
+	return children
+}
@@ -0,0 +1,116 @@
+package gospeak
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// Verbosity selects how much of each declaration is read.
+type Verbosity int
+
+const (
+	// VerbosityFull reads everything, including function bodies.
+	VerbosityFull Verbosity = iota
+	// VerbosityOutline reads only declarations, without bodies, as a spoken
+	// table of contents.
+	VerbosityOutline
+)
+
+func (gsp *goSpeaker) speakOutlineDeclaration(d ast.Decl) {
+	if !gsp.isInRange(d) {
+		return
+	}
+	gsp.enterNode(d)
+	defer gsp.leaveNode()
+
+	switch v := d.(type) {
+	case *ast.FuncDecl:
+		gsp.speakOutlineFunction(v)
+	case *ast.GenDecl:
+		for _, spec := range v.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				gsp.speakOutlineType(s)
+			case *ast.ValueSpec:
+				if v.Tok == token.CONST {
+					gsp.speakOutlineValue(s, "const")
+				} else {
+					gsp.speakOutlineValue(s, "var")
+				}
+			}
+		}
+	}
+}
+
+func (gsp *goSpeaker) speakOutlineFunction(fd *ast.FuncDecl) {
+	gsp.functionStack = append(gsp.functionStack, fd.Name.String())
+	defer func() {
+		gsp.functionStack = gsp.functionStack[:len(gsp.functionStack)-1]
+	}()
+
+	if fd.Recv != nil && len(fd.Recv.List) > 0 {
+		gsp.speakPhrase("Outline method", "Name", gsp.symbolToSpeech(fd.Name.String()))
+		gsp.speakExpr(fd.Recv.List[0].Type, true)
+	} else {
+		gsp.speakPhrase("FuncDecl", "Name", gsp.symbolToSpeech(fd.Name.String()))
+	}
+	if fd.Type.TypeParams != nil && fd.Type.TypeParams.NumFields() > 0 {
+		gsp.speakPhrase("Outline type params", "Count", fd.Type.TypeParams.NumFields())
+	}
+	gsp.speakPhrase("Outline params", "Count", fd.Type.Params.NumFields())
+
+	results := fd.Type.Results
+	if results == nil || results.NumFields() == 0 {
+		return
+	}
+	if results.NumFields() == 1 {
+		gsp.speakPhrase("Outline result")
+		gsp.speakExpr(results.List[0].Type, true)
+	} else {
+		gsp.speakPhrase("Outline results", "Count", results.NumFields())
+	}
+}
+
+func (gsp *goSpeaker) speakOutlineType(ts *ast.TypeSpec) {
+	gsp.enterNode(ts)
+	defer gsp.leaveNode()
+
+	gsp.speakPhrase("TypeSpec", "Name", gsp.symbolToSpeech(ts.Name.String()))
+	if ts.TypeParams != nil && ts.TypeParams.NumFields() > 0 {
+		gsp.speakPhrase("Outline type params", "Count", ts.TypeParams.NumFields())
+	}
+	switch t := ts.Type.(type) {
+	case *ast.StructType:
+		gsp.speakPhrase("Outline struct", "Count", t.Fields.NumFields())
+	case *ast.InterfaceType:
+		gsp.speakPhrase("Outline interface", "Count", interfaceMethods(t))
+	default:
+		gsp.speakExpr(ts.Type, true)
+	}
+}
+
+// interfaceMethods counts the methods an interface declares, leaving out
+// embedded interfaces and type set terms.
+func interfaceMethods(t *ast.InterfaceType) int {
+	count := 0
+	for _, field := range t.Methods.List {
+		if _, ok := field.Type.(*ast.FuncType); ok {
+			count += len(field.Names)
+		}
+	}
+	return count
+}
+
+func (gsp *goSpeaker) speakOutlineValue(vs *ast.ValueSpec, specType string) {
+	gsp.enterNode(vs)
+	defer gsp.leaveNode()
+
+	gsp.speakPhrase("ValueSpec "+specType, "Count", len(vs.Names))
+	for _, name := range vs.Names {
+		gsp.speakSymbol(name.String())
+	}
+	if vs.Type != nil {
+		gsp.speakPhrase("ValueSpec type")
+		gsp.speakExpr(vs.Type, true)
+	}
+}
@@ -0,0 +1,275 @@
+package gospeak
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type goPackage struct {
+	name    string
+	dir     string
+	files   []*ast.File
+	sources map[string]string
+}
+
+func (gsp *goSpeaker) SpeakGoPackage(path string) error {
+	err := gsp.LoadPackage(path)
+	if err != nil {
+		return err
+	}
+	return gsp.SpeakAllContext(context.Background())
+}
+
+// LoadPackage parses the non-test Go files of a package, given either its
+// directory or its import path. Once a package is loaded, SpeakAll reads a
+// package overview followed by each file's declarations.
+func (gsp *goSpeaker) LoadPackage(path string) error {
+	gsp.file = nil
+	gsp.pkg = nil
+
+	dir, err := packageDir(path)
+	if err != nil {
+		return err
+	}
+
+	gsp.fileBuffer = ""
+	gsp.fileSet = token.NewFileSet()
+	gsp.parseErrors = nil
+	gsp.typesInfo = nil
+
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	// Unlike parser.ParseDir, files that only partly parse are kept, so
+	// that they are counted and read along with their errors
+	pkgs := map[string]*ast.Package{}
+	var firstErr error
+	for _, fi := range entries {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), ".go") || strings.HasSuffix(fi.Name(), "_test.go") {
+			continue
+		}
+		filename := filepath.Join(dir, fi.Name())
+		file, err := parser.ParseFile(gsp.fileSet, filename, nil, parser.ParseComments)
+		if err != nil {
+			gsp.logf("Warning: %s had compile errors: %+v\n", filename, err)
+			if errList, ok := err.(scanner.ErrorList); ok {
+				gsp.parseErrors = append(gsp.parseErrors, errList...)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		if file == nil || !file.Package.IsValid() {
+			continue
+		}
+		astPkg, ok := pkgs[file.Name.Name]
+		if !ok {
+			astPkg = &ast.Package{Name: file.Name.Name, Files: map[string]*ast.File{}}
+			pkgs[file.Name.Name] = astPkg
+		}
+		astPkg.Files[filename] = file
+	}
+	if len(pkgs) == 0 {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("no Go files in %s", dir)
+		}
+		return firstErr
+	}
+
+	astPkg := choosePackage(pkgs, dir)
+	pkg := &goPackage{
+		name:    astPkg.Name,
+		dir:     dir,
+		sources: map[string]string{},
+	}
+	filenames := []string{}
+	for filename := range astPkg.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		pkg.files = append(pkg.files, astPkg.Files[filename])
+		source, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		pkg.sources[filename] = string(source)
+	}
+
+	gsp.pkg = pkg
+	return nil
+}
+
+func (gsp *goSpeaker) SetExportedOnly(exportedOnly bool) {
+	gsp.exportedOnly = exportedOnly
+}
+
+func packageDir(path string) (string, error) {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return path, nil
+	}
+	bp, err := build.Import(path, ".", build.FindOnly)
+	if err != nil {
+		return "", fmt.Errorf("can't find package %s: %+v", path, err)
+	}
+	return bp.Dir, nil
+}
+
+// choosePackage picks the package a directory is really about when its
+// files declare more than one, for example a main package next to a library.
+func choosePackage(pkgs map[string]*ast.Package, dir string) *ast.Package {
+	var best *ast.Package
+	for _, pkg := range pkgs {
+		if pkg.Name == filepath.Base(dir) {
+			return pkg
+		}
+		if best == nil || len(pkg.Files) > len(best.Files) ||
+			(len(pkg.Files) == len(best.Files) && pkg.Name < best.Name) {
+			best = pkg
+		}
+	}
+	return best
+}
+
+func (gsp *goSpeaker) speakPackage(pkg *goPackage) {
+	gsp.speakPackageOverview(pkg)
+
+	for _, file := range pkg.files {
+		filename := gsp.fileSet.Position(file.Pos()).Filename
+		gsp.file = file
+		gsp.fileBuffer = pkg.sources[filename]
+
+		gsp.enterNode(file)
+		gsp.speakPhrase("Package file", "Name", speakableFilename(filepath.Base(filename)))
+		gsp.leaveNode()
+		gsp.speakFile(file)
+	}
+	gsp.file = nil
+	gsp.fileBuffer = ""
+}
+
+func (gsp *goSpeaker) speakPackageOverview(pkg *goPackage) {
+	types := []string{}
+	functions := []string{}
+	for _, file := range pkg.files {
+		for _, d := range file.Decls {
+			switch v := d.(type) {
+			case *ast.FuncDecl:
+				if v.Recv == nil && v.Name.IsExported() {
+					functions = append(functions, v.Name.String())
+				}
+			case *ast.GenDecl:
+				if v.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range v.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+						types = append(types, ts.Name.String())
+					}
+				}
+			}
+		}
+	}
+
+	gsp.speakPhrase("Package", "Name", gsp.symbolToSpeech(pkg.name))
+	gsp.speak(gsp.countSpeech(len(pkg.files), "file"))
+	for _, file := range pkg.files {
+		gsp.speak(speakableFilename(filepath.Base(gsp.fileSet.Position(file.Pos()).Filename)))
+	}
+	gsp.speak(gsp.countSpeech(len(types), "exported type"))
+	for _, name := range types {
+		gsp.speakSymbol(name)
+	}
+	gsp.speak(gsp.countSpeech(len(functions), "exported function"))
+	for _, name := range functions {
+		gsp.speakSymbol(name)
+	}
+}
+
+// exportedDecl returns the part of a declaration that belongs to the
+// exported API, or nil if there is none.
+func exportedDecl(d ast.Decl) ast.Decl {
+	switch v := d.(type) {
+	case *ast.FuncDecl:
+		if !v.Name.IsExported() {
+			return nil
+		}
+		if v.Recv != nil && len(v.Recv.List) > 0 && !isExportedType(v.Recv.List[0].Type) {
+			return nil
+		}
+		return v
+	case *ast.GenDecl:
+		if v.Tok == token.IMPORT {
+			return nil
+		}
+		specs := []ast.Spec{}
+		for _, spec := range v.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				if s.Name.IsExported() {
+					specs = append(specs, s)
+				}
+			case *ast.ValueSpec:
+				if exported := exportedValueSpec(s); exported != nil {
+					specs = append(specs, exported)
+				}
+			}
+		}
+		if len(specs) == 0 {
+			return nil
+		}
+		filtered := *v
+		filtered.Specs = specs
+		return &filtered
+	}
+	return nil
+}
+
+// exportedValueSpec keeps only the exported names of a value spec, with
+// their values, or returns nil if none is exported. Values from a single
+// multi-valued expression can't be split, so they are kept whole.
+func exportedValueSpec(vs *ast.ValueSpec) *ast.ValueSpec {
+	filtered := *vs
+	filtered.Names = nil
+	if len(vs.Values) == len(vs.Names) {
+		filtered.Values = nil
+	}
+	for i, name := range vs.Names {
+		if !name.IsExported() {
+			continue
+		}
+		filtered.Names = append(filtered.Names, name)
+		if len(vs.Values) == len(vs.Names) {
+			filtered.Values = append(filtered.Values, vs.Values[i])
+		}
+	}
+	if len(filtered.Names) == 0 {
+		return nil
+	}
+	return &filtered
+}
+
+func isExportedType(expr ast.Expr) bool {
+	switch v := expr.(type) {
+	case *ast.Ident:
+		return v.IsExported()
+	case *ast.StarExpr:
+		return isExportedType(v.X)
+	case *ast.IndexExpr:
+		return isExportedType(v.X)
+	case *ast.IndexListExpr:
+		return isExportedType(v.X)
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package gospeak
+
+import (
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+var quotedToken = regexp.MustCompile(`'[^']*'`)
+
+func (gsp *goSpeaker) ParseErrors() scanner.ErrorList {
+	return gsp.parseErrors
+}
+
+// parseErrorSpeech turns a parser message such as "expected '}', found 'EOF'"
+// into "expected closing brace, found end of file".
+func (gsp *goSpeaker) parseErrorSpeech(msg string) string {
+	return quotedToken.ReplaceAllStringFunc(msg, func(quoted string) string {
+		tok := quoted[1 : len(quoted)-1]
+		if _, ok := englishLocale["Token "+tok]; ok {
+			return gsp.phrase("Token " + tok)
+		}
+		return tok
+	})
+}
+
+func (gsp *goSpeaker) speakAt(speech string, pos token.Position, kind string) {
+	gsp.tracef("Saying: %s\n", speech)
+	event := gsp.makeEvent(speech)
+	event.Start = pos
+	event.End = pos
+	event.Kind = kind
+	gsp.addEvent(event)
+}
+
+func (gsp *goSpeaker) speakParseError(e *scanner.Error) {
+	gsp.speakAt(gsp.phrase("Error", "Line", e.Pos.Line, "Column", e.Pos.Column,
+		"Message", gsp.parseErrorSpeech(e.Msg)), e.Pos, "Error")
+}
+
+func (gsp *goSpeaker) isLineInRange(line int) bool {
+	if gsp.startLine < 0 || gsp.endLine < 0 {
+		return gsp.targetFunction == ""
+	}
+	return line >= gsp.startLine && line <= gsp.endLine
+}
+
+// speakParseErrorSummary announces the syntax errors that fall in the
+// current range of the file being read, before the rest of it is read.
+func (gsp *goSpeaker) speakParseErrorSummary() {
+	filename := gsp.fileSet.Position(gsp.file.Pos()).Filename
+	errs := scanner.ErrorList{}
+	for _, e := range gsp.parseErrors {
+		if e.Pos.Filename == filename && gsp.isLineInRange(e.Pos.Line) {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) == 0 {
+		return
+	}
+	gsp.speakPhrase("Error count", "Count", len(errs))
+	for _, e := range errs {
+		gsp.speakParseError(e)
+	}
+}
+
+// SpeakErrors reads only the syntax errors, each followed by the source
+// lines around it. It reads them even when the file couldn't be loaded
+// because its package clause is broken.
+func (gsp *goSpeaker) SpeakErrors() error {
+	if !gsp.isLoaded() && len(gsp.parseErrors) == 0 {
+		return ErrNothingLoaded
+	}
+	gsp.events = nil
+
+	if len(gsp.parseErrors) == 0 {
+		gsp.speakPhrase("Error none")
+		return gsp.speakBuffer()
+	}
+
+	for _, e := range gsp.parseErrors {
+		lines := gsp.sourceLines(e.Pos.Filename)
+		gsp.speakParseError(e)
+		for line := e.Pos.Line - 1; line <= e.Pos.Line+1; line++ {
+			if line < 1 || line > len(lines) {
+				continue
+			}
+			gsp.speakSourceLine(e.Pos, line, lines[line-1])
+		}
+	}
+	return gsp.speakBuffer()
+}
+
+func (gsp *goSpeaker) speakSourceLine(errPos token.Position, line int, text string) {
+	pos := token.Position{Filename: errPos.Filename, Line: line, Column: 1}
+	if strings.TrimSpace(text) == "" {
+		gsp.speakAt(gsp.phrase("Error blank line", "Line", line), pos, "Source")
+		return
+	}
+	gsp.speakAt(gsp.phrase("Error line", "Line", line), pos, "Source")
+	gsp.speakAt(gsp.sourceLineSpeech(text), pos, "Source")
+}
+
+// sourceLineSpeech reads a line of source, naming its punctuation the way
+// the syntax errors name tokens.
+func (gsp *goSpeaker) sourceLineSpeech(text string) string {
+	words := []string{}
+	for _, sym := range splitSymbol(strings.TrimSpace(text)) {
+		if strings.TrimSpace(sym) == "" {
+			continue
+		}
+		if _, ok := englishLocale["Token "+sym]; ok {
+			words = append(words, gsp.phrase("Token "+sym))
+		} else {
+			words = append(words, gsp.translateSymbols([]string{sym})...)
+		}
+	}
+	return strings.Join(words, " ")
+}
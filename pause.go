@@ -0,0 +1,23 @@
+//go:build !windows
+
+package gospeak
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseProcess suspends the process speaking a phrase, if there is one.
+func pauseProcess(process *os.Process) error {
+	if process == nil {
+		return nil
+	}
+	return process.Signal(syscall.SIGSTOP)
+}
+
+func resumeProcess(process *os.Process) error {
+	if process == nil {
+		return nil
+	}
+	return process.Signal(syscall.SIGCONT)
+}
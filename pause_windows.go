@@ -0,0 +1,23 @@
+package gospeak
+
+import (
+	"errors"
+	"os"
+)
+
+// Windows has no signal to suspend a process with, so only the phrases
+// that haven't started yet are held.
+var errPauseUnsupported = errors.New("speech can only be paused between phrases on Windows")
+
+// pauseProcess fails only when a phrase is being spoken, since the phrases
+// after it are held all the same.
+func pauseProcess(process *os.Process) error {
+	if process == nil {
+		return nil
+	}
+	return errPauseUnsupported
+}
+
+func resumeProcess(process *os.Process) error {
+	return nil
+}
@@ -0,0 +1,150 @@
+package gospeak
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// ContextSpeechBackend is implemented by backends whose speech can be cut
+// short: the speech stops, and SpeakContext returns, when ctx is done.
+type ContextSpeechBackend interface {
+	SpeakContext(ctx context.Context, script string, audioOutputFile string) error
+}
+
+// ContextWAVPlayer is a WAVPlayer whose playing stops when ctx is done.
+type ContextWAVPlayer interface {
+	PlayWAVContext(ctx context.Context, wavFile string) error
+}
+
+// playback is the speech a speaker is playing, which Stop, Pause and
+// Resume control from other goroutines.
+type playback struct {
+	mutex   sync.Mutex
+	cancel  context.CancelFunc
+	process *os.Process
+	// resumed is closed when paused speech goes on; it is nil while playing
+	resumed chan struct{}
+}
+
+type playbackKey struct{}
+
+func (p *playback) pause() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.resumed != nil {
+		return nil
+	}
+	p.resumed = make(chan struct{})
+	return pauseProcess(p.process)
+}
+
+func (p *playback) resume() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.resumed == nil {
+		return nil
+	}
+	close(p.resumed)
+	p.resumed = nil
+	return resumeProcess(p.process)
+}
+
+// run runs a command that plays speech. While the speech is paused it
+// waits to start, and once started it is paused with the rest.
+func (p *playback) run(ctx context.Context, cmd *exec.Cmd) error {
+	p.mutex.Lock()
+	resumed := p.resumed
+	p.mutex.Unlock()
+	if resumed != nil {
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	p.process = cmd.Process
+	if p.resumed != nil {
+		pauseProcess(cmd.Process)
+	}
+	p.mutex.Unlock()
+
+	err := cmd.Wait()
+	p.mutex.Lock()
+	p.process = nil
+	p.mutex.Unlock()
+	return err
+}
+
+// runSpeechCommand runs a command made with exec.CommandContext, which plays
+// or synthesizes speech. If ctx belongs to a speaker's speech, the command
+// is paused and resumed with it. A command cut short returns ctx.Err().
+func runSpeechCommand(ctx context.Context, cmd *exec.Cmd) error {
+	var err error
+	if p, ok := ctx.Value(playbackKey{}).(*playback); ok {
+		err = p.run(ctx, cmd)
+	} else {
+		err = cmd.Run()
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// play starts playing speech that Stop, Pause and Resume can control. The
+// returned context is done once the speech is stopped, and the returned
+// function must be called when it is over.
+func (gsp *goSpeaker) play(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &playback{cancel: cancel}
+	gsp.playMutex.Lock()
+	gsp.playing = p
+	gsp.playMutex.Unlock()
+
+	return context.WithValue(ctx, playbackKey{}, p), func() {
+		cancel()
+		gsp.playMutex.Lock()
+		if gsp.playing == p {
+			gsp.playing = nil
+		}
+		gsp.playMutex.Unlock()
+	}
+}
+
+func (gsp *goSpeaker) currentPlayback() *playback {
+	gsp.playMutex.Lock()
+	defer gsp.playMutex.Unlock()
+	return gsp.playing
+}
+
+// Stop cuts short the speech the speaker is playing, if any, from another
+// goroutine. The call that was speaking returns context.Canceled.
+func (gsp *goSpeaker) Stop() {
+	if p := gsp.currentPlayback(); p != nil {
+		p.cancel()
+	}
+}
+
+// Pause holds the speech the speaker is playing until Resume is called.
+// Pausing part way through a phrase isn't supported on Windows.
+func (gsp *goSpeaker) Pause() error {
+	if p := gsp.currentPlayback(); p != nil {
+		return p.pause()
+	}
+	return nil
+}
+
+// Resume goes on with paused speech.
+func (gsp *goSpeaker) Resume() error {
+	if p := gsp.currentPlayback(); p != nil {
+		return p.resume()
+	}
+	return nil
+}
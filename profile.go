@@ -0,0 +1,263 @@
+package gospeak
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"io/ioutil"
+	"strings"
+)
+
+// Profile rewords the messages of a locale, so that a team can settle on
+// its own phrasing, such as "star T" rather than "pointer to T". Keys are
+// the same as a Locale's. A profile can also give a whole-node template,
+// keyed by the node kind followed by "node", which is given the speech of
+// the node's children and speaks the node in one phrase; for example
+// {"AssignStmt node": "assign {{join .Rhs \"and\"}} to {{join .Lhs \"and\"}}"}.
+//
+// A profile worded for one language gives it as its "Language" entry, such
+// as en, and is only used with locales of that language.
+type Profile map[string]string
+
+var profiles = map[string]Profile{
+	"terse":   terseProfile,
+	"verbose": verboseProfile,
+}
+
+// BuiltinProfile returns one of the profiles that ship with gospeak: terse,
+// which leaves out filler words, or verbose, which spells things out. Both
+// are worded in English.
+func BuiltinProfile(name string) (Profile, error) {
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %s, expected terse or verbose", name)
+	}
+	return profile, nil
+}
+
+// LoadProfile reads a profile from a JSON file mapping message keys to
+// templates.
+func LoadProfile(filename string) (Profile, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	profile := Profile{}
+	err = json.Unmarshal(data, &profile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse profile %s: %+v", filename, err)
+	}
+	return profile, nil
+}
+
+// AppliesTo reports whether a profile can reword a locale: a profile
+// without a language applies to any locale.
+func (profile Profile) AppliesTo(locale Locale) bool {
+	language := profile["Language"]
+	return language == "" || primaryLanguage(language) == primaryLanguage(locale.Language())
+}
+
+// SetProfile rewords the speech with a phrasing profile. Nil goes back to
+// the locale's own wording. A profile for another language than the
+// locale's is not used.
+func (gsp *goSpeaker) SetProfile(profile Profile) {
+	gsp.profile = profile
+	gsp.templates = nil
+}
+
+// catalogs returns the messages to look for a key in, in order.
+func (gsp *goSpeaker) catalogs() []map[string]string {
+	if !gsp.profile.AppliesTo(gsp.locale) {
+		return []map[string]string{gsp.locale, englishLocale}
+	}
+	return []map[string]string{gsp.profile, gsp.locale, englishLocale}
+}
+
+func (gsp *goSpeaker) hasMessage(key string) bool {
+	for _, messages := range gsp.catalogs() {
+		if _, ok := messages[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeFields returns what a whole-node template is given: the node's
+// children, which are spoken before the template is run, and its
+// operator. Nodes that have no whole-node form return nil.
+func (gsp *goSpeaker) nodeFields(n ast.Node) map[string]interface{} {
+	switch v := n.(type) {
+	case *ast.StarExpr:
+		return map[string]interface{}{"X": v.X}
+	case *ast.UnaryExpr:
+		return map[string]interface{}{"Op": v.Op.String(), "X": v.X}
+	case *ast.BinaryExpr:
+		return map[string]interface{}{"Op": v.Op.String(), "X": v.X, "Y": v.Y}
+	case *ast.ParenExpr:
+		return map[string]interface{}{"X": v.X}
+	case *ast.SelectorExpr:
+		return map[string]interface{}{"X": v.X, "Sel": v.Sel}
+	case *ast.IndexExpr:
+		return map[string]interface{}{"X": v.X, "Index": v.Index}
+	case *ast.SliceExpr:
+		return map[string]interface{}{"X": v.X, "Low": v.Low, "High": v.High, "Max": v.Max}
+	case *ast.TypeAssertExpr:
+		return map[string]interface{}{"X": v.X, "Type": v.Type}
+	case *ast.CallExpr:
+		return map[string]interface{}{"Fun": v.Fun, "Args": v.Args}
+	case *ast.KeyValueExpr:
+		return map[string]interface{}{"Key": v.Key, "Value": v.Value}
+	case *ast.ArrayType:
+		return map[string]interface{}{"Len": v.Len, "Elt": v.Elt}
+	case *ast.MapType:
+		return map[string]interface{}{"Key": v.Key, "Value": v.Value}
+	case *ast.ChanType:
+		return map[string]interface{}{"Value": v.Value}
+	case *ast.AssignStmt:
+		return map[string]interface{}{"Op": v.Tok.String(), "Lhs": v.Lhs, "Rhs": v.Rhs,
+			"Operator": gsp.assignOperatorSpeech(v.Tok)}
+	case *ast.IncDecStmt:
+		return map[string]interface{}{"Op": v.Tok.String(), "X": v.X}
+	case *ast.ReturnStmt:
+		return map[string]interface{}{"Results": v.Results}
+	case *ast.SendStmt:
+		return map[string]interface{}{"Chan": v.Chan, "Value": v.Value}
+	case *ast.GoStmt:
+		return map[string]interface{}{"Call": v.Call}
+	case *ast.DeferStmt:
+		return map[string]interface{}{"Call": v.Call}
+	}
+	return nil
+}
+
+// speakNodeTemplate speaks a node with its whole-node template, if there
+// is one. Nodes that are only partly in range, or that hold a function
+// literal with statements of its own, are spoken the usual way.
+func (gsp *goSpeaker) speakNodeTemplate(n ast.Node, isDecl bool) bool {
+	key := nodeKind(n) + " node"
+	if !gsp.hasMessage(key) || !gsp.isStartInRange(n) || !gsp.isEndInRange(n) || hasFuncLit(n) {
+		return false
+	}
+	fields := gsp.nodeFields(n)
+	if fields == nil {
+		return false
+	}
+
+	args := []interface{}{"Decl", isDecl}
+	for name, field := range fields {
+		switch v := field.(type) {
+		case ast.Expr:
+			field = gsp.childSpeech(v, isDecl)
+		case []ast.Expr:
+			speech := []string{}
+			for _, expr := range v {
+				speech = append(speech, gsp.childSpeech(expr, isDecl))
+			}
+			field = speech
+		case nil:
+			field = ""
+		}
+		args = append(args, name, field)
+	}
+	gsp.speakPhrase(key, args...)
+	return true
+}
+
+// childSpeech speaks a child node on its own and returns its phrases. The
+// trace only shows the phrase the template makes of them.
+func (gsp *goSpeaker) childSpeech(expr ast.Expr, isDecl bool) string {
+	saved, verbose := gsp.events, gsp.verboseOutput
+	gsp.events, gsp.verboseOutput = nil, false
+	gsp.speakExpr(expr, isDecl)
+	phrases := []string{}
+	for _, e := range gsp.events {
+		if !e.earcon {
+			phrases = append(phrases, e.Phrase)
+		}
+	}
+	gsp.events, gsp.verboseOutput = saved, verbose
+	return strings.Join(phrases, " ")
+}
+
+func hasFuncLit(n ast.Node) bool {
+	found := false
+	ast.Inspect(n, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+var terseProfile = Profile{
+	"Language":              `en`,
+	"File declarations":     ``,
+	"FuncDecl":              `func {{.Name}}`,
+	"FuncDecl start":        ``,
+	"FuncDecl end":          `end {{.Name}}`,
+	"FuncType params":       `{{if .Count}}{{count .Count "parameter"}}{{end}}`,
+	"FuncType results":      `{{if .Count}}returns {{.Count}}{{end}}`,
+	"FuncLit":               `func`,
+	"FuncLit start":         ``,
+	"FuncLit end":           `end func`,
+	"TypeSpec is":           ``,
+	"ValueSpec type":        ``,
+	"Field type":            ``,
+	"StructType fields":     `{{count .Count "field"}}`,
+	"InterfaceType methods": `{{count .Count "method"}}`,
+	"StarExpr type":         `star`,
+	"StarExpr":              `star`,
+	"ArrayType slice":       `slice`,
+	"ArrayType length":      `array`,
+	"MapType key start":     ``,
+	"MapType key":           `to`,
+	"MapType value start":   ``,
+	"MapType value":         ``,
+	"CompositeLit elements": `of`,
+	"CallExpr node":         `{{.Fun}} {{if .Args}}of {{join .Args "comma"}}{{else}}called{{end}}`,
+	"AssignStmt node":       `{{join .Lhs "and"}} {{if eq .Op "="}}gets{{else if eq .Op ":="}}is{{else}}{{.Operator}} equals{{end}} {{join .Rhs "and"}}`,
+	"BinaryExpr <":          `less than`,
+	"BinaryExpr <=":         `at most`,
+	"BinaryExpr >":          `greater than`,
+	"BinaryExpr >=":         `at least`,
+	"BinaryExpr !=":         `not equals`,
+	"IfStmt start":          ``,
+	"ForStmt start":         ``,
+	"RangeStmt":             `range`,
+	"RangeStmt with":        ``,
+	"RangeStmt start":       ``,
+	"BlockStmt start":       ``,
+}
+
+var verboseProfile = Profile{
+	"Language":           `en`,
+	"FuncDecl":           `declare function {{.Name}}`,
+	"FuncDecl end":       `end of function {{.Name}}`,
+	"FuncLit":            `anonymous function`,
+	"FuncLit end":        `end of anonymous function`,
+	"TypeSpec":           `declare type {{.Name}}`,
+	"ValueSpec const":    `declare {{plural .Count "constant" "constants"}}`,
+	"ValueSpec var":      `declare {{plural .Count "variable" "variables"}}`,
+	"StarExpr":           `the value pointed to by`,
+	"UnaryExpr &":        `the address of`,
+	"IndexExpr":          `at index`,
+	"CallExpr":           `with arguments`,
+	"AssignStmt node":    `{{if eq .Op ":="}}define {{join .Lhs "and"}} as {{join .Rhs "and"}}{{else if eq .Op "="}}assign {{join .Rhs "and"}} to {{join .Lhs "and"}}{{else}}set {{join .Lhs "and"}} to itself {{.Operator}} {{join .Rhs "and"}}{{end}}`,
+	"IncDecStmt node":    `{{if eq .Op "++"}}add one to{{else}}subtract one from{{end}} {{.X}}`,
+	"DeferStmt node":     `when the function returns, {{.Call}}`,
+	"GoStmt node":        `start a goroutine that will {{.Call}}`,
+	"ReturnStmt also":    `and also`,
+	"BinaryExpr ==":      `is equal to`,
+	"BinaryExpr !=":      `is not equal to`,
+	"BinaryExpr &&":      `and also`,
+	"BinaryExpr ||":      `or else`,
+	"ParenExpr start":    `open parenthesis`,
+	"ParenExpr end":      `close parenthesis`,
+	"IfStmt end":         `end of if statement`,
+	"ForStmt end":        `end of {{if .While}}while{{else}}for{{end}} loop`,
+	"RangeStmt end":      `end of range loop`,
+	"SwitchStmt end":     `end of switch statement`,
+	"TypeSwitchStmt end": `end of type switch statement`,
+	"SelectStmt end":     `end of select statement`,
+}
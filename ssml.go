@@ -0,0 +1,126 @@
+package gospeak
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// literalMarker prefixes phrases that hold the contents of a string literal,
+// so that markup-aware backends can treat them differently.
+const literalMarker = "{literal}"
+
+// escapeMarker prefixes the name of an escape sequence in a string literal,
+// which is spoken as a phrase of its own so that a newline escape can be
+// told apart from the word newline.
+const escapeMarker = "{escape}"
+
+func stripMarkers(script string) string {
+	script = strings.Replace(strings.Replace(script, literalMarker, "", -1), escapeMarker, "", -1)
+	return earconMarker.ReplaceAllString(script, "")
+}
+
+// ssmlKeywords are emphasized in English speech. Other languages have their
+// own words for them, so nothing is emphasized there.
+var ssmlKeywords = []string{
+	"function", "end function", "function body", "lambda", "end lambda",
+	"if", "then", "else", "end if", "with initializer", "when",
+	"for", "for ever", "while", "do", "end for loop", "end while loop",
+	"range over", "range body", "end range",
+	"switch", "end switch", "end type switch", "case", "default",
+	"select", "end select", "return", "defer", "go",
+	"break", "continue", "goto", "fallthrough",
+	"package", "imports", "declarations", "type", "var", "vars",
+	"constant", "constants", "struct", "interface",
+}
+
+func init() {
+	// Longer keywords first so that "end function" wins over "end".
+	sort.SliceStable(ssmlKeywords, func(i, j int) bool {
+		return len(ssmlKeywords[i]) > len(ssmlKeywords[j])
+	})
+}
+
+type ssmlBackend struct {
+	voice VoiceSettings
+	out   io.Writer
+}
+
+// MakeSSMLBackend returns a backend that writes each speech script as an
+// SSML 1.1 document, either to the audio output file when one is given or
+// to out. Piping the document into an SSML-capable engine is left to the
+// caller.
+func MakeSSMLBackend(voice VoiceSettings, out io.Writer) SpeechBackend {
+	return &ssmlBackend{
+		voice: voice,
+		out:   out,
+	}
+}
+
+func (sb *ssmlBackend) Speak(script string, audioOutputFile string) error {
+	doc := RenderSSML(script, sb.voice)
+	if audioOutputFile != "" {
+		err := ioutil.WriteFile(audioOutputFile, []byte(doc), 0644)
+		if err != nil {
+			return fmt.Errorf("unable to write %s: %+v", audioOutputFile, err)
+		}
+		return nil
+	}
+	_, err := io.WriteString(sb.out, doc)
+	return err
+}
+
+func RenderSSML(script string, voice VoiceSettings) string {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	sb.WriteString("<speak version=\"1.1\" xmlns=\"http://www.w3.org/2001/10/synthesis\" xml:lang=\"" +
+		escapeXML(voice.language()) + "\">\n")
+	if voice.Voice != "" {
+		sb.WriteString("<voice name=\"" + escapeXML(voice.Voice) + "\">\n")
+	}
+	if voice.Rate > 0 {
+		sb.WriteString(fmt.Sprintf("<prosody rate=\"%d%%\">\n", voice.Rate*100/175))
+	}
+	for _, phrase := range strings.Split(script, "{pause}") {
+		phrase = strings.TrimSpace(phrase)
+		if phrase == "" {
+			continue
+		}
+		if earcon, ok := parseEarcon(phrase); ok {
+			// SSML can't describe a tone, so leave a gap where it would be
+			sb.WriteString(fmt.Sprintf("<break time=\"%dms\"/>\n", earcon.Duration))
+			continue
+		}
+		sb.WriteString(ssmlPhrase(phrase, voice.isEnglish()))
+		sb.WriteString(" <break time=\"200ms\"/>\n")
+	}
+	if voice.Rate > 0 {
+		sb.WriteString("</prosody>\n")
+	}
+	if voice.Voice != "" {
+		sb.WriteString("</voice>\n")
+	}
+	sb.WriteString("</speak>\n")
+	return sb.String()
+}
+
+func ssmlPhrase(phrase string, emphasize bool) string {
+	if strings.HasPrefix(phrase, escapeMarker) {
+		return escapeXML(strings.TrimPrefix(phrase, escapeMarker))
+	}
+	if strings.HasPrefix(phrase, literalMarker) {
+		return "<say-as interpret-as=\"text\">" +
+			escapeXML(strings.TrimPrefix(phrase, literalMarker)) + "</say-as>"
+	}
+	if !emphasize {
+		return escapeXML(phrase)
+	}
+	for _, kw := range ssmlKeywords {
+		if phrase == kw || strings.HasPrefix(phrase, kw+" ") {
+			return "<emphasis>" + kw + "</emphasis>" + escapeXML(phrase[len(kw):])
+		}
+	}
+	return escapeXML(phrase)
+}
@@ -0,0 +1,238 @@
+package gospeak
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/types"
+	"strings"
+)
+
+// SetTypeChecked turns on type-aware speech. The loaded source is run
+// through go/types so that, for example, new variables are read with their
+// types and method calls with their receiver's type. If type checking
+// fails, speech falls back to the syntax alone; soft errors such as an
+// unused variable don't count.
+func (gsp *goSpeaker) SetTypeChecked(typeChecked bool) {
+	gsp.typeChecked = typeChecked
+	gsp.typesInfo = nil
+}
+
+func (gsp *goSpeaker) checkTypes() {
+	// Nothing is kept from an earlier check, so a failed one reads syntax only
+	gsp.typesInfo = nil
+	gsp.typesPkg = nil
+	if !gsp.typeChecked {
+		return
+	}
+
+	var files []*ast.File
+	name := ""
+	if gsp.pkg != nil {
+		files = gsp.pkg.files
+		name = gsp.pkg.name
+	} else if gsp.file != nil {
+		files = []*ast.File{gsp.file}
+		name = gsp.file.Name.String()
+	}
+	if len(files) == 0 || len(gsp.parseErrors) > 0 {
+		return
+	}
+
+	info := &types.Info{
+		Types:      map[ast.Expr]types.TypeAndValue{},
+		Defs:       map[*ast.Ident]types.Object{},
+		Uses:       map[*ast.Ident]types.Object{},
+		Selections: map[*ast.SelectorExpr]*types.Selection{},
+	}
+	// Soft errors, such as an unused variable, still leave the types known
+	hardError := false
+	conf := types.Config{
+		Importer: importer.ForCompiler(gsp.fileSet, "source", nil),
+		Error: func(err error) {
+			if terr, ok := err.(types.Error); ok && terr.Soft {
+				gsp.tracef("Type checking: %+v\n", err)
+				return
+			}
+			hardError = true
+			gsp.logf("Type checking failed, reading syntax only: %+v\n", err)
+		},
+	}
+	pkg, _ := conf.Check(name, gsp.fileSet, files, info)
+	if hardError {
+		return
+	}
+	gsp.typesInfo = info
+	gsp.typesPkg = pkg
+}
+
+// typeSpeech describes a type the way a declaration would be read. Types
+// from other packages are qualified with the package name.
+func (gsp *goSpeaker) typeSpeech(t types.Type) string {
+	switch v := types.Unalias(t).(type) {
+	case *types.Basic:
+		return gsp.symbolToSpeech(strings.TrimPrefix(v.Name(), "untyped "))
+	case *types.Pointer:
+		return gsp.phrase("Type pointer", "Elem", gsp.typeSpeech(v.Elem()))
+	case *types.Slice:
+		return gsp.phrase("Type slice", "Elem", gsp.typeSpeech(v.Elem()))
+	case *types.Array:
+		return gsp.phrase("Type array", "Len", v.Len(), "Elem", gsp.typeSpeech(v.Elem()))
+	case *types.Map:
+		return gsp.phrase("Type map", "Key", gsp.typeSpeech(v.Key()), "Elem", gsp.typeSpeech(v.Elem()))
+	case *types.Chan:
+		return gsp.phrase("Type channel", "Elem", gsp.typeSpeech(v.Elem()))
+	case *types.Signature:
+		return gsp.phrase("Type function")
+	case *types.Struct:
+		return gsp.phrase("Type struct")
+	case *types.Interface:
+		if v.Empty() {
+			return gsp.phrase("Type empty interface")
+		}
+		return gsp.phrase("Type interface")
+	case *types.TypeParam:
+		return gsp.symbolToSpeech(v.Obj().Name())
+	case *types.Named:
+		name := v.Obj().Name()
+		if pkg := v.Obj().Pkg(); pkg != nil && pkg != gsp.typesPkg {
+			name = pkg.Name() + "." + name
+		}
+		return gsp.symbolToSpeech(name)
+	}
+	return gsp.symbolToSpeech(t.String())
+}
+
+// articleSounds are word beginnings that don't sound the way their first
+// letter does, such as the "you" of user and unique or the silent h of hour.
+// The first match wins, so un- words come before uni.
+var articleSounds = []struct {
+	prefix string
+	vowel  bool
+}{
+	{"unin", true},
+	{"unim", true},
+	{"uni", false},
+	{"uint", false},
+	{"usa", false},
+	{"use", false},
+	{"usu", false},
+	{"uti", false},
+	{"eu", false},
+	{"one", false},
+	{"once", false},
+	{"hour", true},
+	{"honest", true},
+	{"honor", true},
+	{"heir", true},
+}
+
+// withArticle puts "a" or "an" before speech by how it sounds rather than
+// how it is spelled, so a user, a uint and an hour, and HTTP, read letter
+// by letter, is "an H T T P".
+func withArticle(speech string) string {
+	words := strings.Fields(speech)
+	if len(words) == 0 {
+		return speech
+	}
+	first := strings.ToLower(words[0])
+	vowel := strings.ContainsRune("aeiou", rune(first[0]))
+	if len(first) == 1 {
+		// A letter is read by its name
+		vowel = strings.ContainsRune("aefhilmnorsx", rune(first[0]))
+	} else {
+		for _, sound := range articleSounds {
+			if strings.HasPrefix(first, sound.prefix) {
+				vowel = sound.vowel
+				break
+			}
+		}
+	}
+	if vowel {
+		return "an " + speech
+	}
+	return "a " + speech
+}
+
+// speakDefinedType reads the type of a newly defined identifier, as in
+// "x, an int, equal ...".
+func (gsp *goSpeaker) speakDefinedType(expr ast.Expr) {
+	if gsp.typesInfo == nil || !gsp.isEndInRange(expr) {
+		return
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok || ident.Name == "_" {
+		return
+	}
+	if obj, ok := gsp.typesInfo.Defs[ident].(*types.Var); ok && obj != nil {
+		gsp.speakPhrase("Type defined", "Type", gsp.typeSpeech(obj.Type()))
+	}
+}
+
+func (gsp *goSpeaker) exprType(expr ast.Expr) types.Type {
+	if gsp.typesInfo == nil {
+		return nil
+	}
+	if tv, ok := gsp.typesInfo.Types[expr]; ok {
+		return tv.Type
+	}
+	return nil
+}
+
+// speakTypedSelector reads a selector using type information: package
+// qualifiers, fields and method values are each named for what they are.
+// It reports false if there is no type information for sel.
+func (gsp *goSpeaker) speakTypedSelector(sel *ast.SelectorExpr, isCall bool) bool {
+	if gsp.typesInfo == nil {
+		return false
+	}
+
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		if _, ok := gsp.typesInfo.Uses[ident].(*types.PkgName); ok {
+			if gsp.isStartInRange(sel) {
+				gsp.speakPhrase("SelectorExpr package")
+			}
+			return false
+		}
+	}
+
+	selection, ok := gsp.typesInfo.Selections[sel]
+	if !ok {
+		return false
+	}
+
+	switch selection.Kind() {
+	case types.MethodVal:
+		if gsp.isStartInRange(sel) {
+			if isCall {
+				gsp.speakPhrase("SelectorExpr method", "Name", gsp.symbolToSpeech(sel.Sel.String()))
+			} else {
+				gsp.speakPhrase("SelectorExpr method value", "Name", gsp.symbolToSpeech(sel.Sel.String()))
+			}
+		}
+		gsp.speakExpr(sel.X, false)
+		if gsp.isEndInRange(sel) {
+			gsp.speakPhrase("SelectorExpr receiver", "Type", gsp.typeSpeech(selection.Recv()))
+		}
+	case types.FieldVal:
+		if gsp.isStartInRange(sel) {
+			gsp.speakPhrase("SelectorExpr field", "Name", gsp.symbolToSpeech(sel.Sel.String()))
+		}
+		gsp.speakExpr(sel.X, false)
+		if gsp.isEndInRange(sel) {
+			recv := selection.Recv()
+			if ptr, ok := recv.Underlying().(*types.Pointer); ok {
+				recv = ptr.Elem()
+			}
+			speech := gsp.typeSpeech(recv)
+			if _, ok := recv.(*types.Named); ok {
+				if _, ok := recv.Underlying().(*types.Struct); ok {
+					speech = gsp.phrase("Type named struct", "Name", speech)
+				}
+			}
+			gsp.speakPhrase("SelectorExpr owner", "Type", speech)
+		}
+	default:
+		return false
+	}
+	return true
+}